@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/joescharf/gsi/internal/config"
+	"github.com/joescharf/gsi/internal/scaffold"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var addCmd = &cobra.Command{
+	Use:   "add <capability>...",
+	Short: "Add capabilities to an existing Go project",
+	Long: `Add runs only the scaffold steps that belong to the named capabilities
+against an existing Go module. The module path is read from go.mod.
+
+Capabilities: ` + strings.Join(scaffold.CapabilityNames(), ", ") + `
+
+Examples:
+  gsi add docker
+  gsi add docs release --dry-run
+  gsi add --on-conflict=prompt makefile
+  gsi add --dir ../other-project goreleaser`,
+	Args:      cobra.MinimumNArgs(1),
+	ValidArgs: scaffold.CapabilityNames(),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir, _ := cmd.Flags().GetString("dir")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		verbose, _ := cmd.Flags().GetBool("verbose")
+		keepFailed, _ := cmd.Flags().GetBool("keep-on-failure")
+		jobs, _ := cmd.Flags().GetInt("jobs")
+		absDir, err := filepath.Abs(dir)
+		if err != nil {
+			return fmt.Errorf("resolving directory: %w", err)
+		}
+
+		onConflict, err := conflictPolicy(cmd)
+		if err != nil {
+			return err
+		}
+
+		cfg := scaffold.Config{
+			Author:       viper.GetString("author"),
+			DryRun:       dryRun,
+			Verbose:      verbose,
+			OnConflict:   onConflict,
+			Version:      buildVersion,
+			Packs:        packSources(cmd, false),
+			PackCacheDir: config.PackCacheDir(),
+			Offline:      offlineSetting(cmd),
+			ArtifactDir:  config.ArtifactCacheDir(),
+			KeepFailed:   keepFailed,
+			Jobs:         jobs,
+			ProjectDir:   absDir,
+		}
+		return newScaffolder(cmd, cfg).Add(args)
+	},
+}
+
+func init() {
+	addCmd.Flags().String("dir", ".", "Project directory containing go.mod")
+	addCmd.Flags().BoolP("dry-run", "d", false, "Show what would be done without executing")
+	addCmd.Flags().BoolP("verbose", "v", false, "Enable verbose output")
+	addConflictFlag(addCmd)
+	addPackFlag(addCmd)
+	addOfflineFlag(addCmd)
+	addKeepFlag(addCmd)
+	addJobsFlag(addCmd)
+	rootCmd.AddCommand(addCmd)
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"github.com/joescharf/gsi/internal/config"
+	"github.com/joescharf/gsi/internal/scaffold"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the artifact cache used by --offline",
+}
+
+var cacheWarmCmd = &cobra.Command{
+	Use:   "warm [artifact...]",
+	Short: "Download everything an --offline scaffold needs",
+	Long: `Warm runs the steps of a scaffold that need the network in throwaway
+projects and keeps what they fetch, so 'gsi --offline' can scaffold without
+one. Run it while online; by default it fills every artifact:
+
+  go-modules   the Go modules a project with every capability on requires
+  bmad         the output of npx bmad-method install
+  docs         the docs pyproject.toml and uv.lock, and the packages they lock
+  ui           the ui/ skeleton from bun init, node_modules included
+
+The packs from the config file and --pack are fetched into the pack cache
+and rendered when resolving Go modules, so their dependencies are cached too.
+
+Artifacts are kept in ` + config.ArtifactCacheDir() + `
+
+Examples:
+  gsi cache warm
+  gsi cache warm go-modules docs
+  gsi cache warm --pack https://github.com/acme/gsi-pack.git@v1`,
+	ValidArgs: scaffold.Artifacts,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		verbose, _ := cmd.Flags().GetBool("verbose")
+		s := newScaffolder(cmd, scaffold.Config{
+			Author:       viper.GetString("author"),
+			ModulePrefix: viper.GetString(config.KeyModulePrefix),
+			Verbose:      verbose,
+			Version:      buildVersion,
+			Packs:        packSources(cmd, true),
+			PackCacheDir: config.PackCacheDir(),
+			ArtifactDir:  config.ArtifactCacheDir(),
+		})
+		return s.WarmCache(args)
+	},
+}
+
+func init() {
+	cacheWarmCmd.Flags().BoolP("verbose", "v", false, "Enable verbose output")
+	addPackFlag(cacheWarmCmd)
+	cacheCmd.AddCommand(cacheWarmCmd)
+	rootCmd.AddCommand(cacheCmd)
+}
@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/joescharf/gsi/internal/config"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Manage gsi's user configuration",
+}
+
+var configInitCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Create the config directory and default config file",
+	Long: `Create the config directory and default config file.
+
+Asks for the author and Go module prefix first; press Enter to keep the value
+shown in brackets (the author defaults to git's user.name and user.email).`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfgDir := config.ConfigDir()
+
+		if err := config.EnsureDir(cfgDir); err != nil {
+			return fmt.Errorf("create config directory: %w", err)
+		}
+		fmt.Fprintln(os.Stderr, "Config directory:", cfgDir)
+
+		cfgPath := config.DefaultConfigFile()
+
+		// Only write if file does not already exist
+		if _, err := os.Stat(cfgPath); err == nil {
+			fmt.Fprintln(os.Stderr, "Config file already exists:", cfgPath)
+			return nil
+		}
+
+		in := bufio.NewReader(cmd.InOrStdin())
+		for _, p := range initPrompts {
+			viper.Set(p.key, prompt(in, p.label, viper.GetString(p.key)))
+		}
+
+		if err := config.SaveConfig(cfgPath); err != nil {
+			return fmt.Errorf("write config file: %w", err)
+		}
+		fmt.Fprintln(os.Stderr, "Config file created:", cfgPath)
+		return nil
+	},
+}
+
+var configEditCmd = &cobra.Command{
+	Use:   "edit",
+	Short: "Open the config file in your editor",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfgPath := config.DefaultConfigFile()
+		if cfgFile != "" {
+			cfgPath = cfgFile
+		}
+
+		if _, err := os.Stat(cfgPath); os.IsNotExist(err) {
+			return fmt.Errorf("config file not found: %s (run 'gsi config init' first)", cfgPath)
+		}
+
+		editor := os.Getenv("EDITOR")
+		if editor == "" {
+			fmt.Fprintln(os.Stderr, "Config file:", cfgPath)
+			fmt.Fprintln(os.Stderr, "Set $EDITOR to open it automatically")
+			return nil
+		}
+
+		c := editorCommand(editor, cfgPath)
+		c.Stdin = os.Stdin
+		c.Stdout = os.Stdout
+		c.Stderr = os.Stderr
+		return c.Run()
+	},
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Display the resolved configuration values",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if used := viper.ConfigFileUsed(); used != "" {
+			fmt.Fprintf(os.Stderr, "Config file: %s\n", used)
+		} else {
+			fmt.Fprintln(os.Stderr, "Config file: (none found, using defaults)")
+		}
+		fmt.Fprintln(os.Stderr, "")
+
+		for _, key := range config.Settings {
+			fmt.Fprintf(os.Stdout, "%s: %s\n", key, showSetting(key))
+		}
+		fmt.Fprintf(os.Stdout, "%s:\n", config.KeyCapabilities)
+		caps := config.Capabilities()
+		for _, c := range capabilities {
+			fmt.Fprintf(os.Stdout, "  %s: %v\n", c.name, caps[c.name])
+		}
+		return nil
+	},
+}
+
+// initPrompts are the settings 'gsi config init' asks for, since no built-in
+// default suits everyone.
+var initPrompts = []struct{ key, label string }{
+	{config.KeyAuthor, "Author (Name <email>)"},
+	{config.KeyModulePrefix, "Go module prefix (e.g. github.com/you)"},
+}
+
+// prompt asks for label on stderr and reads the answer from in, returning def
+// for an empty answer or when in is exhausted.
+func prompt(in *bufio.Reader, label, def string) string {
+	fmt.Fprintf(os.Stderr, "%s [%s]: ", label, def)
+	answer, _ := in.ReadString('\n')
+	if answer = strings.TrimSpace(answer); answer != "" {
+		return answer
+	}
+	return def
+}
+
+// editorCommand opens path in editor, which like $EDITOR for git may carry
+// arguments (e.g. "code --wait"), so it is run by the shell.
+func editorCommand(editor, path string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		args := strings.Fields(editor)
+		return exec.Command(args[0], append(args[1:], path)...)
+	}
+	return exec.Command("sh", "-c", editor+` "$@"`, editor, path)
+}
+
+// showSetting formats the resolved value of a config key, lists as
+// comma-separated values.
+func showSetting(key string) string {
+	switch viper.Get(key).(type) {
+	case []any, []string:
+		return strings.Join(viper.GetStringSlice(key), ", ")
+	}
+	return viper.GetString(key)
+}
+
+func init() {
+	configCmd.AddCommand(configInitCmd)
+	configCmd.AddCommand(configEditCmd)
+	configCmd.AddCommand(configShowCmd)
+	rootCmd.AddCommand(configCmd)
+}
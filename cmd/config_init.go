@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/joescharf/gsi/internal/config"
+	"github.com/joescharf/gsi/internal/logger"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// cfgFile holds the --config-file flag value. The flag is not named --config
+// because that name belongs to the config capability toggle.
+var cfgFile string
+
+func init() {
+	cobra.OnInitialize(initConfig)
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config-file", "", "config file (default: "+config.DefaultConfigFile()+")")
+	rootCmd.PersistentFlags().String("templates-dir", "", "directory of template overrides, searched before "+config.TemplatesDir())
+	_ = viper.BindPFlag(config.KeyTemplatesDir, rootCmd.PersistentFlags().Lookup("templates-dir"))
+}
+
+func initConfig() {
+	if err := config.InitViper(cfgFile); err != nil {
+		logger.New(false).Error("reading config file: " + err.Error())
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"go.yaml.in/yaml/v3"
+)
+
+var planCmd = &cobra.Command{
+	Use:   "plan [project-name]",
+	Short: "Print a machine-readable plan of what a scaffold would do",
+	Long: `Plan performs a silent dry run and prints a structured document listing
+the resolved capabilities, every step and whether it runs, every file that
+would be created, overwritten or skipped, and every external command with
+its working directory.
+
+Accepts the same scaffold flags as the root command.
+
+Examples:
+  gsi plan --json my-app
+  gsi plan --yaml --profile library my-lib
+  gsi plan --json . | jq '.files[] | select(.action == "overwrite")'`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		asYAML, _ := cmd.Flags().GetBool("yaml")
+		asJSON, _ := cmd.Flags().GetBool("json")
+		if asYAML && asJSON {
+			return fmt.Errorf("--json and --yaml are mutually exclusive")
+		}
+
+		cfg, err := scaffoldConfig(cmd, args[0])
+		if err != nil {
+			return err
+		}
+
+		doc, err := newScaffolder(cmd, cfg).BuildPlan()
+		if err != nil {
+			return err
+		}
+
+		if asYAML {
+			enc := yaml.NewEncoder(os.Stdout)
+			enc.SetIndent(2)
+			if err := enc.Encode(doc); err != nil {
+				return fmt.Errorf("encoding plan: %w", err)
+			}
+			return enc.Close()
+		}
+
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(doc)
+	},
+}
+
+func init() {
+	addScaffoldFlags(planCmd)
+	planCmd.Flags().Bool("json", false, "Output the plan as JSON (default)")
+	planCmd.Flags().Bool("yaml", false, "Output the plan as YAML")
+	rootCmd.AddCommand(planCmd)
+}
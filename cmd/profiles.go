@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/joescharf/gsi/internal/config"
+	"github.com/joescharf/gsi/internal/scaffold"
+	"github.com/spf13/cobra"
+)
+
+var profilesCmd = &cobra.Command{
+	Use:   "profiles",
+	Short: "Manage capability profiles",
+}
+
+var profilesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List built-in and user-defined profiles and the capabilities they enable",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		profiles, err := config.Profiles()
+		if err != nil {
+			return err
+		}
+
+		for _, name := range config.ProfileNames(profiles) {
+			p := profiles[name]
+			source := "user"
+			if p.BuiltIn {
+				source = "built-in"
+			}
+
+			// Resolve against the configured defaults so the listing shows
+			// exactly what --profile <name> would produce.
+			caps := config.Capabilities()
+			p.Apply(caps)
+			var on, off []string
+			for _, capName := range scaffold.CapabilityNames() {
+				if caps[capName] {
+					on = append(on, capName)
+				} else {
+					off = append(off, capName)
+				}
+			}
+
+			fmt.Fprintf(os.Stdout, "%s (%s)\n", p.Name, source)
+			if p.Description != "" {
+				fmt.Fprintf(os.Stdout, "  %s\n", p.Description)
+			}
+			fmt.Fprintf(os.Stdout, "  enables:  %s\n", strings.Join(on, ", "))
+			fmt.Fprintf(os.Stdout, "  disables: %s\n", strings.Join(off, ", "))
+			fmt.Fprintln(os.Stdout)
+		}
+		return nil
+	},
+}
+
+func init() {
+	profilesCmd.AddCommand(profilesListCmd)
+	rootCmd.AddCommand(profilesCmd)
+}
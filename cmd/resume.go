@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/joescharf/gsi/internal/config"
+	"github.com/joescharf/gsi/internal/scaffold"
+	"github.com/spf13/cobra"
+)
+
+var resumeCmd = &cobra.Command{
+	Use:   "resume",
+	Short: "Continue a failed scaffold from the step that failed",
+	Long: `Resume continues a scaffold that failed.
+
+While scaffolding, gsi checkpoints each completed step in .gsi/state.yaml
+together with a hash of its inputs: for a template file, the content it
+renders to, and for a command, the settings it uses and the gsi version.
+Resume reuses the recorded settings, skips the completed steps and runs the
+failed step and those that did not complete. In a directory that existed
+before the run, the checkpoint survives the rollback of a failed run, which
+undoes the completed steps, so without --keep-on-failure resume runs the
+scaffold again from the start with the same settings. A directory the run
+created is removed entirely; run the same command again instead.
+
+Flags given to resume replace the recorded settings. A step whose inputs
+changed as a result runs again, and so does every step that depends on it;
+--no-ui, say, only reruns the files whose templates mention the UI.
+
+A resume that fails is rolled back to the state it started from, so it can
+be resumed again.
+
+Examples:
+  gsi --keep-on-failure --ui my-app   # fails at the UI step
+  cd my-app && gsi resume
+  cd existing-app && gsi --ui .       # fails and is rolled back
+  gsi resume
+  gsi resume --dir my-app --no-ui`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir, _ := cmd.Flags().GetString("dir")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		verbose, _ := cmd.Flags().GetBool("verbose")
+		keepFailed, _ := cmd.Flags().GetBool("keep-on-failure")
+		jobs, _ := cmd.Flags().GetInt("jobs")
+		absDir, err := filepath.Abs(dir)
+		if err != nil {
+			return fmt.Errorf("resolving directory: %w", err)
+		}
+		onConflict, err := conflictPolicy(cmd)
+		if err != nil {
+			return err
+		}
+
+		// Only settings given on the command line replace recorded ones
+		caps := map[string]bool{}
+		capabilityOverrides(cmd, caps)
+		cfg := scaffold.Config{
+			DryRun:       dryRun,
+			Verbose:      verbose,
+			OnConflict:   onConflict,
+			Version:      buildVersion,
+			Packs:        packSources(cmd, false),
+			PackCacheDir: config.PackCacheDir(),
+			Offline:      offlineSetting(cmd),
+			ArtifactDir:  config.ArtifactCacheDir(),
+			KeepFailed:   keepFailed,
+			Jobs:         jobs,
+			Capabilities: caps,
+			ProjectDir:   absDir,
+		}
+		for flag, setting := range map[string]*string{
+			"author":      &cfg.Author,
+			"description": &cfg.Description,
+			"license":     &cfg.License,
+			"ci":          &cfg.CI,
+			"repo-url":    &cfg.RepoURL,
+		} {
+			if cmd.Flags().Changed(flag) {
+				*setting, _ = cmd.Flags().GetString(flag)
+			}
+		}
+		return newScaffolder(cmd, cfg).Resume()
+	},
+}
+
+func init() {
+	resumeCmd.Flags().String("dir", ".", "Project directory containing "+scaffold.StateName)
+	resumeCmd.Flags().BoolP("dry-run", "d", false, "Show what would be done without executing")
+	resumeCmd.Flags().BoolP("verbose", "v", false, "Enable verbose output")
+	resumeCmd.Flags().StringP("author", "a", "", "Author name and email (default: as recorded)")
+	resumeCmd.Flags().String("description", "", "One-line project description (default: as recorded)")
+	resumeCmd.Flags().String("license", "", "License SPDX id (default: as recorded)")
+	resumeCmd.Flags().String("ci", "", "CI provider (default: as recorded)")
+	resumeCmd.Flags().String("repo-url", "", "Repository URL (default: as recorded)")
+	addCapabilityFlags(resumeCmd, false)
+	addConflictFlag(resumeCmd)
+	addPackFlag(resumeCmd)
+	addOfflineFlag(resumeCmd)
+	addKeepFlag(resumeCmd)
+	addJobsFlag(resumeCmd)
+	rootCmd.AddCommand(resumeCmd)
+}
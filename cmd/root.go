@@ -1,9 +1,14 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 
+	"github.com/joescharf/gsi/internal/config"
 	"github.com/joescharf/gsi/internal/scaffold"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -39,14 +44,24 @@ mkdocs-material documentation, an embedded web UI, mockery, editorconfig,
 and optional React/shadcn/Tailwind frontend.
 
 Each capability can be toggled with --<name> / --no-<name> flags.
-Defaults: most capabilities ON, ui OFF.
+Defaults: most capabilities ON, ui OFF. Defaults for author, module prefix
+and capabilities can be set in ~/.config/gsi/config.yaml (see 'gsi config')
+or via GSI_* environment variables.
 
 Examples:
   gsi my-awesome-app
   gsi --author "Jane Doe jane@example.com" my-app
+  gsi --description "Inventory sync service" --license Apache-2.0 my-app
   gsi --module github.com/myorg/myapp --dry-run my-app
+  gsi --module go.acme.dev/app --repo-url https://gitlab.com/acme/tools/app app
+  gsi --ci gitlab my-app
+  gsi --offline my-app   # after 'gsi cache warm'
+  gsi --dry-run --diff .
+  gsi --on-conflict=backup .
+  gsi --pack https://github.com/acme/gsi-pack.git@v1 my-app
   gsi --no-bmad --no-git my-app
   gsi --no-docker --no-release my-app
+  gsi --profile library my-lib
   gsi --only-docs my-app
   gsi --ui my-app
   gsi .    # Initialize in current directory`,
@@ -56,32 +71,201 @@ Examples:
 			return fmt.Errorf("project name is required (use '.' for current directory)")
 		}
 
-		// Build capabilities map from defaults, then apply flag overrides
-		caps := scaffold.DefaultCapabilities()
-		for _, cap := range capabilities {
-			noFlag := "no-" + cap.name
-			// --no-<name> takes precedence if explicitly set
-			if cmd.Flags().Changed(noFlag) {
-				noVal, _ := cmd.Flags().GetBool(noFlag)
-				caps[cap.name] = !noVal
-			} else if cmd.Flags().Changed(cap.name) {
-				val, _ := cmd.Flags().GetBool(cap.name)
-				caps[cap.name] = val
-			}
+		cfg, err := scaffoldConfig(cmd, args[0])
+		if err != nil {
+			return err
 		}
+		cfg.DryRun = viper.GetBool("dry-run")
+		cfg.Verbose = viper.GetBool("verbose")
+		cfg.Diff, _ = cmd.Flags().GetBool("diff")
 
-		cfg := scaffold.Config{
-			ProjectName:  args[0],
-			Author:       viper.GetString("author"),
-			GoModulePath: viper.GetString("module"),
-			DryRun:       viper.GetBool("dry-run"),
-			Verbose:      viper.GetBool("verbose"),
-			OnlyDocs:     viper.GetBool("only-docs"),
-			Capabilities: caps,
+		return newScaffolder(cmd, cfg).Run()
+	},
+}
+
+// scaffoldConfig builds a scaffold.Config from the scaffold flags registered
+// by addScaffoldFlags on cmd, falling back to viper (config file, env vars,
+// defaults) for anything not set on the command line.
+func scaffoldConfig(cmd *cobra.Command, projectName string) (scaffold.Config, error) {
+	// Build capabilities map from configured defaults, then the selected
+	// profile, then apply flag overrides
+	caps := config.Capabilities()
+	profileName := stringSetting(cmd, "profile", config.KeyProfile)
+	if profileName != "" {
+		profile, err := config.Profile(profileName)
+		if err != nil {
+			return scaffold.Config{}, err
 		}
+		profile.Apply(caps)
+		profileName = profile.Name
+	}
+	capabilityOverrides(cmd, caps)
 
-		return scaffold.NewScaffolder(cfg).Run()
-	},
+	onConflict, err := conflictPolicy(cmd)
+	if err != nil {
+		return scaffold.Config{}, err
+	}
+
+	license, err := scaffold.ParseLicense(stringSetting(cmd, "license", config.KeyLicense))
+	if err != nil {
+		return scaffold.Config{}, err
+	}
+	ci, err := scaffold.ParseCI(stringSetting(cmd, "ci", config.KeyCI))
+	if err != nil {
+		return scaffold.Config{}, err
+	}
+
+	onlyDocs := viper.GetBool("only-docs")
+	if cmd.Flags().Changed("only-docs") {
+		onlyDocs, _ = cmd.Flags().GetBool("only-docs")
+	}
+	keepFailed, _ := cmd.Flags().GetBool("keep-on-failure")
+	jobs, _ := cmd.Flags().GetInt("jobs")
+
+	return scaffold.Config{
+		ProjectName:  projectName,
+		Author:       stringSetting(cmd, "author", "author"),
+		Description:  stringSetting(cmd, "description", "description"),
+		License:      license,
+		CI:           ci,
+		GoModulePath: stringSetting(cmd, "module", "module"),
+		RepoURL:      stringSetting(cmd, "repo-url", "repo-url"),
+		ModulePrefix: viper.GetString(config.KeyModulePrefix),
+		OnlyDocs:     onlyDocs,
+		Profile:      profileName,
+		OnConflict:   onConflict,
+		Version:      buildVersion,
+		Packs:        packSources(cmd, true),
+		PackCacheDir: config.PackCacheDir(),
+		Offline:      offlineSetting(cmd),
+		ArtifactDir:  config.ArtifactCacheDir(),
+		KeepFailed:   keepFailed,
+		Jobs:         jobs,
+		Capabilities: caps,
+	}, nil
+}
+
+// newScaffolder returns a Scaffolder for cfg whose commands stop when cmd's
+// context is cancelled (see Execute) and are logged under config.LogDir, and
+// whose templates may be overridden from config.TemplateSearchPath.
+func newScaffolder(cmd *cobra.Command, cfg scaffold.Config) *scaffold.Scaffolder {
+	cfg.LogDir = config.LogDir()
+	cfg.TemplateDirs = config.TemplateSearchPath()
+	s := scaffold.NewScaffolder(cfg)
+	s.Executor.Context = cmd.Context()
+	return s
+}
+
+// conflictPolicy returns the validated --on-conflict value for cmd.
+func conflictPolicy(cmd *cobra.Command) (scaffold.ConflictPolicy, error) {
+	return scaffold.ParseConflictPolicy(stringSetting(cmd, "on-conflict", "on-conflict"))
+}
+
+// packSources returns the --pack values given on cmd. Without any, and when
+// useConfig is set, it falls back to the packs config list.
+func packSources(cmd *cobra.Command, useConfig bool) []string {
+	if cmd.Flags().Changed("pack") {
+		packs, _ := cmd.Flags().GetStringArray("pack")
+		return packs
+	}
+	if useConfig {
+		return viper.GetStringSlice(config.KeyPacks)
+	}
+	return nil
+}
+
+// offlineSetting returns --offline if it was set on cmd, else the offline
+// config key (GSI_OFFLINE), so air-gapped hosts can turn it on once.
+func offlineSetting(cmd *cobra.Command) bool {
+	if cmd.Flags().Changed("offline") {
+		offline, _ := cmd.Flags().GetBool("offline")
+		return offline
+	}
+	return viper.GetBool(config.KeyOffline)
+}
+
+// stringSetting returns the flag value if it was set on cmd, else the viper key.
+func stringSetting(cmd *cobra.Command, flag, key string) string {
+	if cmd.Flags().Changed(flag) {
+		val, _ := cmd.Flags().GetString(flag)
+		return val
+	}
+	return viper.GetString(key)
+}
+
+// addScaffoldFlags registers the flags shared by every command that resolves
+// a scaffold configuration (author, module, profile and capability toggles).
+func addScaffoldFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP("author", "a", "", "Author name and email (default: git config user.name <user.email>)")
+	cmd.Flags().StringP("module", "m", "", "Go module path (default: <module-prefix>/<project>)")
+	cmd.Flags().String("repo-url", "", "Repository URL, for module paths that do not name it (e.g., https://gitlab.com/group/sub/app)")
+	cmd.Flags().String("description", "", "One-line project description (default: \"<project> CLI application\")")
+	cmd.Flags().String("license", scaffold.DefaultLicense, "License SPDX id: "+strings.Join(scaffold.Licenses, ", "))
+	cmd.Flags().String("ci", "", "CI provider: "+strings.Join(scaffold.CIProviders, ", ")+" (default: the repository's forge)")
+	cmd.Flags().Bool("only-docs", false, "Only add docs scaffolding (skip everything else)")
+	cmd.Flags().StringP("profile", "p", "", "Capability profile to start from (see 'gsi profiles list')")
+	addConflictFlag(cmd)
+	addPackFlag(cmd)
+	addOfflineFlag(cmd)
+
+	addCapabilityFlags(cmd, true)
+}
+
+// addCapabilityFlags registers --<name> and a hidden --no-<name> on cmd for
+// every capability, showing the built-in defaults when withDefaults is set.
+func addCapabilityFlags(cmd *cobra.Command, withDefaults bool) {
+	for _, cap := range capabilities {
+		def := cap.defaultValue && withDefaults
+		cmd.Flags().Bool(cap.name, def, cap.description)
+		cmd.Flags().Bool("no-"+cap.name, !def, "Disable "+cap.description)
+		_ = cmd.Flags().MarkHidden("no-" + cap.name)
+	}
+}
+
+// capabilityOverrides sets the capabilities in caps whose flags were given
+// on cmd.
+func capabilityOverrides(cmd *cobra.Command, caps map[string]bool) {
+	for _, cap := range capabilities {
+		noFlag := "no-" + cap.name
+		// --no-<name> takes precedence if explicitly set
+		if cmd.Flags().Changed(noFlag) {
+			noVal, _ := cmd.Flags().GetBool(noFlag)
+			caps[cap.name] = !noVal
+		} else if cmd.Flags().Changed(cap.name) {
+			val, _ := cmd.Flags().GetBool(cap.name)
+			caps[cap.name] = val
+		}
+	}
+}
+
+// addConflictFlag registers --on-conflict on cmd.
+func addConflictFlag(cmd *cobra.Command) {
+	cmd.Flags().String("on-conflict", string(scaffold.ConflictSkip),
+		"What to do with existing hand-edited files: skip, overwrite, backup, prompt or merge")
+}
+
+// addPackFlag registers the repeatable --pack on cmd.
+func addPackFlag(cmd *cobra.Command) {
+	cmd.Flags().StringArray("pack", nil,
+		"Template pack to layer over the built-in templates: a directory or git URL[@ref] (repeatable)")
+}
+
+// addOfflineFlag registers --offline on cmd.
+func addOfflineFlag(cmd *cobra.Command) {
+	cmd.Flags().Bool("offline", false,
+		"Never use the network: take Go modules, BMAD, docs and UI from the cache filled by 'gsi cache warm'")
+}
+
+// addKeepFlag registers --keep-on-failure on cmd.
+func addKeepFlag(cmd *cobra.Command) {
+	cmd.Flags().Bool("keep-on-failure", false,
+		"Leave a failed run's changes in place for debugging instead of rolling back")
+}
+
+// addJobsFlag registers --jobs on cmd.
+func addJobsFlag(cmd *cobra.Command) {
+	cmd.Flags().Int("jobs", scaffold.DefaultJobs,
+		"How many independent steps to run at once (1 runs them one at a time)")
 }
 
 var (
@@ -96,24 +280,28 @@ func Execute(version, commit, date string) {
 	buildCommit = commit
 	buildDate = date
 
-	if err := rootCmd.Execute(); err != nil {
+	// The first Ctrl-C cancels running commands and lets gsi report where
+	// it stopped; a second one exits at once.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	err := rootCmd.ExecuteContext(ctx)
+	stop()
+	if err != nil {
 		os.Exit(1)
 	}
 }
 
 func init() {
-	rootCmd.Flags().StringP("author", "a", "Joe Scharf joe@joescharf.com", "Author name and email")
-	rootCmd.Flags().StringP("module", "m", "", "Go module path (default: github.com/joescharf/<project>)")
+	addScaffoldFlags(rootCmd)
 	rootCmd.Flags().BoolP("dry-run", "d", false, "Show what would be done without executing")
 	rootCmd.Flags().BoolP("verbose", "v", false, "Enable verbose output")
-	rootCmd.Flags().Bool("only-docs", false, "Only add docs scaffolding (skip everything else)")
-
-	// Register capability flags: --<name> and hidden --no-<name>
-	for _, cap := range capabilities {
-		rootCmd.Flags().Bool(cap.name, cap.defaultValue, cap.description)
-		rootCmd.Flags().Bool("no-"+cap.name, !cap.defaultValue, "Disable "+cap.description)
-		_ = rootCmd.Flags().MarkHidden("no-" + cap.name)
-	}
+	rootCmd.Flags().Bool("diff", false, "Show unified diffs for existing files that would change")
+	addKeepFlag(rootCmd)
+	addJobsFlag(rootCmd)
 
 	// Bind non-capability flags to viper
 	_ = viper.BindPFlag("author", rootCmd.Flags().Lookup("author"))
@@ -121,7 +309,5 @@ func init() {
 	_ = viper.BindPFlag("dry-run", rootCmd.Flags().Lookup("dry-run"))
 	_ = viper.BindPFlag("verbose", rootCmd.Flags().Lookup("verbose"))
 	_ = viper.BindPFlag("only-docs", rootCmd.Flags().Lookup("only-docs"))
-
-	// Set defaults via viper
-	viper.SetDefault("author", "Joe Scharf joe@joescharf.com")
+	_ = viper.BindPFlag(config.KeyProfile, rootCmd.Flags().Lookup("profile"))
 }
@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/joescharf/gsi/internal/config"
+	"github.com/joescharf/gsi/internal/scaffold"
+	"github.com/joescharf/gsi/internal/templates"
+	"github.com/spf13/cobra"
+)
+
+var templatesCmd = &cobra.Command{
+	Use:   "templates",
+	Short: "Inspect and customize the templates gsi renders",
+}
+
+var templatesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List templates and where each resolves from",
+	Long: `List every template gsi can render, partials included, with the file it
+resolves from: an override directory, a template pack (from the config file
+or --pack), or "built-in". Templates that only a pack or override directory
+provides are listed too.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s := newScaffolder(cmd, scaffold.Config{
+			Packs:        packSources(cmd, true),
+			PackCacheDir: config.PackCacheDir(),
+		})
+		r, err := s.TemplateRenderer()
+		if err != nil {
+			return err
+		}
+		for _, name := range r.Names() {
+			_, source, err := r.Lookup(name)
+			if err != nil {
+				return err
+			}
+			if source == "" {
+				source = "built-in"
+			}
+			fmt.Fprintf(os.Stdout, "%-40s %s\n", name, source)
+		}
+		return nil
+	},
+}
+
+var templatesExportCmd = &cobra.Command{
+	Use:   "export <name|all>...",
+	Short: "Copy built-in templates out as a starting point for overrides",
+	Long: `Export copies the built-in source of the named templates (or all of
+them) into a directory, by default the user override directory. gsi renders
+a template from the first override directory that has a file of the same
+name, so editing an exported copy changes what gsi generates.
+
+Override directories, in lookup order:
+  --templates-dir, the templates-dir config key, or GSI_TEMPLATES_DIR
+  ` + config.TemplatesDir() + `
+
+Examples:
+  gsi templates export dockerfile.tmpl makefile.tmpl
+  gsi templates export all --dir ./company-templates`,
+	Args:      cobra.MinimumNArgs(1),
+	ValidArgs: append(templates.Names(), "all"),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir, _ := cmd.Flags().GetString("dir")
+		force, _ := cmd.Flags().GetBool("force")
+		if dir == "" {
+			dir = config.TemplatesDir()
+		}
+
+		names := args
+		for _, arg := range args {
+			if arg == "all" {
+				names = templates.Names()
+				break
+			}
+		}
+
+		for _, name := range names {
+			written, err := templates.Export(dir, name, force)
+			if err != nil {
+				return fmt.Errorf("exporting %s: %w", name, err)
+			}
+			if written {
+				fmt.Fprintln(os.Stderr, "Exported", name)
+			} else {
+				fmt.Fprintln(os.Stderr, "Skipped", name, "(already exists; use --force to overwrite)")
+			}
+		}
+		fmt.Fprintln(os.Stderr, "Templates directory:", dir)
+		return nil
+	},
+}
+
+var templatesValidateCmd = &cobra.Command{
+	Use:   "validate [name...]",
+	Short: "Render every template and check the output parses",
+	Long: `Validate renders each file of the template pack with sample project data,
+once for each of several settings (the defaults, everything on, everything
+off, the defaults with each capability flipped, and everything on under each
+CI provider and each license), and checks the output by file type:
+
+  .go                  parses and is gofmt-formatted
+  .yml .yaml           parses as YAML
+  .json .toml .ini     parses
+  .sh                  parses as sh or bash (by its shebang)
+
+Other files are only checked to render. Overrides, the packs from the config
+file and --pack are applied, so this checks what gsi would generate. Pass
+step or template names to validate only those files.
+
+Examples:
+  gsi templates validate
+  gsi templates validate goreleaser ci-workflow
+  gsi templates validate --pack ./company-pack`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s := newScaffolder(cmd, scaffold.Config{
+			Packs:        packSources(cmd, true),
+			PackCacheDir: config.PackCacheDir(),
+		})
+		report, err := s.ValidateTemplates(args)
+		if err != nil {
+			return err
+		}
+		for _, p := range report.Problems {
+			fmt.Fprintln(os.Stdout, p)
+		}
+		fmt.Fprintf(os.Stderr, "Checked %d files, %d distinct renders across %d capability sets\n",
+			report.Files, report.Renders, report.Sets)
+		if n := len(report.Problems); n > 0 {
+			cmd.SilenceUsage = true
+			return fmt.Errorf("%d template problem(s)", n)
+		}
+		fmt.Fprintln(os.Stderr, "All templates are valid")
+		return nil
+	},
+}
+
+func init() {
+	addPackFlag(templatesListCmd)
+	addPackFlag(templatesValidateCmd)
+	templatesExportCmd.Flags().String("dir", "", "Directory to export into (default: "+config.TemplatesDir()+")")
+	templatesExportCmd.Flags().Bool("force", false, "Overwrite templates that already exist in the directory")
+	templatesCmd.AddCommand(templatesListCmd)
+	templatesCmd.AddCommand(templatesExportCmd)
+	templatesCmd.AddCommand(templatesValidateCmd)
+	rootCmd.AddCommand(templatesCmd)
+}
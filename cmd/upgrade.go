@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/joescharf/gsi/internal/config"
+	"github.com/joescharf/gsi/internal/scaffold"
+	"github.com/spf13/cobra"
+)
+
+var upgradeCmd = &cobra.Command{
+	Use:   "upgrade",
+	Short: "Re-apply the current templates to a project scaffolded by gsi",
+	Long: `Upgrade re-renders every file listed in the project's .gsi.yaml manifest
+with this version of gsi's templates.
+
+Files you have not edited are replaced. Edited files are three-way merged
+against the render gsi saved in .gsi/baseline when it last wrote them, so
+template improvements land without losing local changes; overlapping
+changes are written with conflict markers. Edited files with no baseline
+(projects scaffolded by an older gsi) are handled by --on-conflict.
+
+Template packs recorded in the manifest are fetched again at the ref they
+were requested with (a branch moves forward, a tag stays put); --pack
+replaces them.
+
+Examples:
+  gsi upgrade --dry-run --diff
+  gsi upgrade
+  gsi upgrade --dir ../other-project --on-conflict=merge
+  gsi upgrade --pack https://github.com/acme/gsi-pack.git@v2`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir, _ := cmd.Flags().GetString("dir")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		verbose, _ := cmd.Flags().GetBool("verbose")
+		diff, _ := cmd.Flags().GetBool("diff")
+		absDir, err := filepath.Abs(dir)
+		if err != nil {
+			return fmt.Errorf("resolving directory: %w", err)
+		}
+		onConflict, err := conflictPolicy(cmd)
+		if err != nil {
+			return err
+		}
+
+		cfg := scaffold.Config{
+			DryRun:       dryRun,
+			Diff:         diff,
+			Verbose:      verbose,
+			OnConflict:   onConflict,
+			Version:      buildVersion,
+			Packs:        packSources(cmd, false),
+			PackCacheDir: config.PackCacheDir(),
+			Offline:      offlineSetting(cmd),
+			ArtifactDir:  config.ArtifactCacheDir(),
+			ProjectDir:   absDir,
+		}
+		_, err = newScaffolder(cmd, cfg).Upgrade()
+		return err
+	},
+}
+
+func init() {
+	upgradeCmd.Flags().String("dir", ".", "Project directory containing .gsi.yaml")
+	upgradeCmd.Flags().BoolP("dry-run", "d", false, "Show what would be done without executing")
+	upgradeCmd.Flags().BoolP("verbose", "v", false, "Enable verbose output")
+	upgradeCmd.Flags().Bool("diff", false, "Show unified diffs for files that would change")
+	addConflictFlag(upgradeCmd)
+	addPackFlag(upgradeCmd)
+	addOfflineFlag(upgradeCmd)
+	rootCmd.AddCommand(upgradeCmd)
+}
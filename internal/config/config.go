@@ -0,0 +1,261 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/joescharf/gsi/internal/scaffold"
+	"github.com/spf13/viper"
+)
+
+// appName is used for the config directory and env prefix.
+const appName = "gsi"
+
+// Config keys persisted to the user config file.
+const (
+	KeyAuthor       = "author"
+	KeyModulePrefix = "module-prefix"
+	KeyCapabilities = "capabilities"
+	KeyProfile      = "profile"
+	KeyProfiles     = "profiles"
+	KeyTemplatesDir = "templates-dir"
+	KeyPacks        = "packs"
+	KeyLicense      = "license"
+	KeyCI           = "ci"
+	KeyOffline      = "offline"
+)
+
+// Settings lists the keys, besides the capability defaults, that SaveConfig
+// persists and 'gsi config show' prints.
+var Settings = []string{
+	KeyAuthor, KeyModulePrefix, KeyLicense, KeyCI, KeyProfile, KeyTemplatesDir, KeyPacks, KeyOffline,
+}
+
+// ConfigDir returns the gsi configuration directory:
+// $XDG_CONFIG_HOME/gsi, falling back to ~/.config/gsi.
+func ConfigDir() string {
+	base := os.Getenv("XDG_CONFIG_HOME")
+	if base == "" {
+		home, _ := os.UserHomeDir()
+		base = filepath.Join(home, ".config")
+	}
+	return filepath.Join(base, appName)
+}
+
+// DefaultConfigFile returns the default config file path.
+func DefaultConfigFile() string {
+	return filepath.Join(ConfigDir(), "config.yaml")
+}
+
+// CacheDir returns the gsi cache directory:
+// $XDG_CACHE_HOME/gsi, falling back to ~/.cache/gsi.
+func CacheDir() string {
+	base := os.Getenv("XDG_CACHE_HOME")
+	if base == "" {
+		home, _ := os.UserHomeDir()
+		base = filepath.Join(home, ".cache")
+	}
+	return filepath.Join(base, appName)
+}
+
+// LogDir returns where command logs are written:
+// $XDG_STATE_HOME/gsi/logs, falling back to ~/.local/state/gsi/logs.
+func LogDir() string {
+	base := os.Getenv("XDG_STATE_HOME")
+	if base == "" {
+		home, _ := os.UserHomeDir()
+		base = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(base, appName, "logs")
+}
+
+// PackCacheDir returns where git template packs are cloned.
+func PackCacheDir() string {
+	return filepath.Join(CacheDir(), "packs")
+}
+
+// ArtifactCacheDir returns where 'gsi cache warm' stores the artifacts used
+// by --offline.
+func ArtifactCacheDir() string {
+	return filepath.Join(CacheDir(), "artifacts")
+}
+
+// TemplatesDir returns the user template override directory:
+// $XDG_CONFIG_HOME/gsi/templates, falling back to ~/.config/gsi/templates.
+func TemplatesDir() string {
+	return filepath.Join(ConfigDir(), "templates")
+}
+
+// TemplateSearchPath returns the template override directories in lookup
+// order: the configured templates-dir (--templates-dir, config key or
+// GSI_TEMPLATES_DIR), then TemplatesDir.
+func TemplateSearchPath() []string {
+	var dirs []string
+	if dir := viper.GetString(KeyTemplatesDir); dir != "" {
+		dirs = append(dirs, dir)
+	}
+	return append(dirs, TemplatesDir())
+}
+
+// EnsureDir creates a directory (and parents) with mode 0o700 if it doesn't exist.
+func EnsureDir(dir string) error {
+	return os.MkdirAll(dir, 0o700)
+}
+
+// GitAuthor returns git's user.name and user.email as "Name <email>", the
+// name alone when no email is set, or "" when git has no user configured or
+// is not installed.
+func GitAuthor() string {
+	name := gitConfig("user.name")
+	if name == "" {
+		return ""
+	}
+	if email := gitConfig("user.email"); email != "" {
+		return name + " <" + email + ">"
+	}
+	return name
+}
+
+func gitConfig(key string) string {
+	out, err := exec.Command("git", "config", "--get", key).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// SetDefaults configures all Viper defaults for gsi.
+func SetDefaults() {
+	viper.SetDefault(KeyAuthor, GitAuthor())
+	viper.SetDefault(KeyLicense, scaffold.DefaultLicense)
+
+	for name, enabled := range scaffold.DefaultCapabilities() {
+		viper.SetDefault(capabilityKey(name), enabled)
+	}
+}
+
+// InitViper sets up config file discovery, GSI_* env vars, and defaults.
+// A missing config file is not an error; a malformed one is.
+func InitViper(cfgFile string) error {
+	SetDefaults()
+
+	if cfgFile != "" {
+		viper.SetConfigFile(cfgFile)
+	} else {
+		viper.SetConfigName("config")
+		viper.SetConfigType("yaml")
+		viper.AddConfigPath(ConfigDir())
+	}
+
+	viper.SetEnvPrefix(appName)
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_", "-", "_"))
+	viper.AutomaticEnv()
+
+	if err := viper.ReadInConfig(); err != nil {
+		if _, ok := err.(viper.ConfigFileNotFoundError); ok {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// Capabilities returns the configured default for every known capability,
+// layering config file and GSI_CAPABILITIES_<NAME> env values over the
+// built-in scaffold defaults.
+func Capabilities() map[string]bool {
+	caps := scaffold.DefaultCapabilities()
+	for name := range caps {
+		caps[name] = viper.GetBool(capabilityKey(name))
+	}
+	return caps
+}
+
+// SaveConfig writes the persisted gsi settings (see Settings) and the
+// capability defaults to the given path, leaving out settings that are
+// unset. Transient flags such as dry-run are deliberately left out.
+func SaveConfig(path string) error {
+	out := viper.New()
+	for _, key := range Settings {
+		if v := viper.Get(key); !unset(v) {
+			out.Set(key, v)
+		}
+	}
+	out.Set(KeyCapabilities, Capabilities())
+	return out.WriteConfigAs(path)
+}
+
+// unset reports whether a setting's value is empty: nil, a zero value or an
+// empty list.
+func unset(v any) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Slice {
+		return rv.Len() == 0
+	}
+	return rv.IsZero()
+}
+
+// Profiles returns the built-in profiles merged with those defined under the
+// profiles: key of the user config. A user profile replaces a built-in
+// profile of the same name.
+//
+//	profiles:
+//	  internal-lib:
+//	    description: Internal library
+//	    capabilities:
+//	      docker: false
+func Profiles() (map[string]scaffold.Profile, error) {
+	profiles := scaffold.BuiltinProfiles()
+
+	for name := range viper.GetStringMap(KeyProfiles) {
+		base := KeyProfiles + "." + name
+		p := scaffold.Profile{
+			Name:         name,
+			Description:  viper.GetString(base + ".description"),
+			Capabilities: map[string]bool{},
+		}
+		for capName := range viper.GetStringMap(base + "." + KeyCapabilities) {
+			if !scaffold.IsCapability(capName) {
+				return nil, fmt.Errorf("profile %q: unknown capability %q", name, capName)
+			}
+			p.Capabilities[capName] = viper.GetBool(base + "." + capabilityKey(capName))
+		}
+		profiles[name] = p
+	}
+	return profiles, nil
+}
+
+// Profile looks up a built-in or user-defined profile by name.
+func Profile(name string) (scaffold.Profile, error) {
+	profiles, err := Profiles()
+	if err != nil {
+		return scaffold.Profile{}, err
+	}
+	p, ok := profiles[strings.ToLower(name)]
+	if !ok {
+		return scaffold.Profile{}, fmt.Errorf("unknown profile %q (available: %s)", name, strings.Join(ProfileNames(profiles), ", "))
+	}
+	return p, nil
+}
+
+// ProfileNames returns the names of the given profiles in sorted order.
+func ProfileNames(profiles map[string]scaffold.Profile) []string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func capabilityKey(name string) string {
+	return KeyCapabilities + "." + name
+}
@@ -0,0 +1,244 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestConfigDirUsesXDG(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", "/tmp/xdg")
+	if got := ConfigDir(); got != filepath.Join("/tmp/xdg", "gsi") {
+		t.Errorf("ConfigDir() = %q, want /tmp/xdg/gsi", got)
+	}
+	if got := DefaultConfigFile(); got != filepath.Join("/tmp/xdg", "gsi", "config.yaml") {
+		t.Errorf("DefaultConfigFile() = %q", got)
+	}
+}
+
+func TestInitViperDefaults(t *testing.T) {
+	viper.Reset()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	if err := InitViper(""); err != nil {
+		t.Fatalf("InitViper failed: %v", err)
+	}
+	if got := viper.GetString(KeyAuthor); got != GitAuthor() {
+		t.Errorf("author = %q, want %q", got, GitAuthor())
+	}
+	if got := viper.GetString(KeyModulePrefix); got != "" {
+		t.Errorf("module-prefix = %q, want none", got)
+	}
+	caps := Capabilities()
+	if caps["ui"] || !caps["docker"] {
+		t.Errorf("expected built-in capability defaults, got %v", caps)
+	}
+}
+
+func TestGitAuthor(t *testing.T) {
+	t.Chdir(t.TempDir())
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	global := filepath.Join(t.TempDir(), "gitconfig")
+	t.Setenv("GIT_CONFIG_GLOBAL", global)
+
+	tests := []struct {
+		config, want string
+	}{
+		{"", ""},
+		{"[user]\n\tname = Jane Doe\n", "Jane Doe"},
+		{"[user]\n\tname = Jane Doe\n\temail = jane@example.com\n", "Jane Doe <jane@example.com>"},
+		{"[user]\n\temail = jane@example.com\n", ""},
+	}
+	for _, tt := range tests {
+		if err := os.WriteFile(global, []byte(tt.config), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if got := GitAuthor(); got != tt.want {
+			t.Errorf("GitAuthor() with %q = %q, want %q", tt.config, got, tt.want)
+		}
+	}
+}
+
+func TestInitViperReadsConfigFile(t *testing.T) {
+	viper.Reset()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := "author: Jane Doe jane@example.com\nmodule-prefix: gitlab.com/acme\ncapabilities:\n  docker: false\n  ui: true\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := InitViper(path); err != nil {
+		t.Fatalf("InitViper failed: %v", err)
+	}
+	if got := viper.GetString(KeyAuthor); got != "Jane Doe jane@example.com" {
+		t.Errorf("author = %q", got)
+	}
+	if got := viper.GetString(KeyModulePrefix); got != "gitlab.com/acme" {
+		t.Errorf("module-prefix = %q", got)
+	}
+	caps := Capabilities()
+	if caps["docker"] {
+		t.Error("expected docker disabled by config file")
+	}
+	if !caps["ui"] {
+		t.Error("expected ui enabled by config file")
+	}
+	if !caps["bmad"] {
+		t.Error("expected unset capabilities to keep their defaults")
+	}
+}
+
+func TestInitViperEnvOverrides(t *testing.T) {
+	viper.Reset()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("GSI_MODULE_PREFIX", "go.example.com")
+	t.Setenv("GSI_CAPABILITIES_BMAD", "false")
+
+	if err := InitViper(""); err != nil {
+		t.Fatalf("InitViper failed: %v", err)
+	}
+	if got := viper.GetString(KeyModulePrefix); got != "go.example.com" {
+		t.Errorf("module-prefix = %q", got)
+	}
+	if Capabilities()["bmad"] {
+		t.Error("expected bmad disabled by env var")
+	}
+}
+
+func TestInitViperMalformedFile(t *testing.T) {
+	viper.Reset()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("author: [unterminated"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := InitViper(path); err == nil {
+		t.Error("expected error for malformed config file")
+	}
+}
+
+func TestSaveConfig(t *testing.T) {
+	viper.Reset()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	if err := InitViper(""); err != nil {
+		t.Fatal(err)
+	}
+	viper.Set("dry-run", true)
+	viper.Set(KeyAuthor, "Jane Doe <jane@example.com>")
+	viper.Set(KeyModulePrefix, "github.com/jane")
+	viper.Set(KeyCI, "gitlab")
+	viper.Set(KeyPacks, []string{"https://example.com/pack.git"})
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := SaveConfig(path); err != nil {
+		t.Fatalf("SaveConfig failed: %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"author: Jane Doe <jane@example.com>", "module-prefix: github.com/jane", "license: MIT", "ci: gitlab", "- https://example.com/pack.git", "capabilities:", "docker: true"} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("saved config missing %q:\n%s", want, content)
+		}
+	}
+	if strings.Contains(string(content), "dry-run") {
+		t.Errorf("saved config should not contain transient flags:\n%s", content)
+	}
+	if strings.Contains(string(content), KeyOffline) || strings.Contains(string(content), KeyTemplatesDir) {
+		t.Errorf("saved config should leave out unset settings:\n%s", content)
+	}
+}
+
+func TestProfilesMergesUserProfiles(t *testing.T) {
+	viper.Reset()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := `profiles:
+  internal-lib:
+    description: Internal library
+    capabilities:
+      docker: false
+      git: false
+  library:
+    capabilities:
+      docs: false
+`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := InitViper(path); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := Profile("internal-lib")
+	if err != nil {
+		t.Fatalf("Profile failed: %v", err)
+	}
+	if p.BuiltIn || p.Description != "Internal library" {
+		t.Errorf("unexpected profile %+v", p)
+	}
+	if p.Capabilities["docker"] || p.Capabilities["git"] || len(p.Capabilities) != 2 {
+		t.Errorf("unexpected capabilities %v", p.Capabilities)
+	}
+
+	// User profile replaces the built-in of the same name
+	lib, err := Profile("library")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lib.BuiltIn || len(lib.Capabilities) != 1 {
+		t.Errorf("expected user library profile to replace built-in, got %+v", lib)
+	}
+
+	if _, err := Profile("cli"); err != nil {
+		t.Errorf("expected built-in cli profile to remain: %v", err)
+	}
+}
+
+func TestProfileUnknown(t *testing.T) {
+	viper.Reset()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	if err := InitViper(""); err != nil {
+		t.Fatal(err)
+	}
+	_, err := Profile("nope")
+	if err == nil || !strings.Contains(err.Error(), "library") {
+		t.Errorf("expected unknown profile error listing available profiles, got %v", err)
+	}
+}
+
+func TestProfilesRejectsUnknownCapability(t *testing.T) {
+	viper.Reset()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("profiles:\n  bad:\n    capabilities:\n      dockr: true\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := InitViper(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Profiles(); err == nil {
+		t.Error("expected error for unknown capability in profile")
+	}
+}
+
+func TestTemplateSearchPath(t *testing.T) {
+	viper.Reset()
+	xdg := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+	if err := InitViper(""); err != nil {
+		t.Fatal(err)
+	}
+
+	userDir := filepath.Join(xdg, "gsi", "templates")
+	if got := TemplateSearchPath(); len(got) != 1 || got[0] != userDir {
+		t.Errorf("TemplateSearchPath() = %v, want [%s]", got, userDir)
+	}
+
+	t.Setenv("GSI_TEMPLATES_DIR", "/srv/templates")
+	if got := TemplateSearchPath(); len(got) != 2 || got[0] != "/srv/templates" || got[1] != userDir {
+		t.Errorf("TemplateSearchPath() = %v, want configured dir first", got)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
+	"sync"
 )
 
 const (
@@ -19,6 +21,8 @@ type Logger struct {
 	Verbose bool
 	Stdout  io.Writer
 	Stderr  io.Writer
+
+	mu sync.Mutex // keeps messages and flushed blocks whole
 }
 
 // New returns a Logger that writes to os.Stdout and os.Stderr.
@@ -31,28 +35,109 @@ func New(verbose bool) *Logger {
 }
 
 func (l *Logger) Info(msg string) {
-	fmt.Fprintf(l.Stdout, "%sℹ%s %s\n", colorBlue, colorReset, msg)
+	l.write(l.Stdout, fmt.Sprintf("%sℹ%s %s\n", colorBlue, colorReset, msg))
 }
 
 func (l *Logger) Success(msg string) {
-	fmt.Fprintf(l.Stdout, "%s✓%s %s\n", colorGreen, colorReset, msg)
+	l.write(l.Stdout, fmt.Sprintf("%s✓%s %s\n", colorGreen, colorReset, msg))
 }
 
 func (l *Logger) Warning(msg string) {
-	fmt.Fprintf(l.Stderr, "%s⚠%s %s\n", colorYellow, colorReset, msg)
+	l.write(l.Stderr, fmt.Sprintf("%s⚠%s %s\n", colorYellow, colorReset, msg))
 }
 
 func (l *Logger) Error(msg string) {
-	fmt.Fprintf(l.Stderr, "%s✗%s %s\n", colorRed, colorReset, msg)
+	l.write(l.Stderr, fmt.Sprintf("%s✗%s %s\n", colorRed, colorReset, msg))
 }
 
 func (l *Logger) VerboseMsg(msg string) {
 	if l.Verbose {
-		fmt.Fprintf(l.Stdout, "%s  →%s %s\n", colorBlue, colorReset, msg)
+		l.write(l.Stdout, fmt.Sprintf("%s  →%s %s\n", colorBlue, colorReset, msg))
 	}
 }
 
 // Plain prints a line without any icon prefix (for config display, etc.).
 func (l *Logger) Plain(msg string) {
-	fmt.Fprintln(l.Stdout, msg)
+	l.write(l.Stdout, msg+"\n")
+}
+
+// Diff prints a unified diff, coloring added and removed lines.
+func (l *Logger) Diff(diff string) {
+	var b strings.Builder
+	for _, line := range strings.SplitAfter(diff, "\n") {
+		if line == "" {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+			b.WriteString(line)
+		case strings.HasPrefix(line, "+"):
+			b.WriteString(colorGreen + strings.TrimSuffix(line, "\n") + colorReset + "\n")
+		case strings.HasPrefix(line, "-"):
+			b.WriteString(colorRed + strings.TrimSuffix(line, "\n") + colorReset + "\n")
+		case strings.HasPrefix(line, "@@"):
+			b.WriteString(colorBlue + strings.TrimSuffix(line, "\n") + colorReset + "\n")
+		default:
+			b.WriteString(line)
+		}
+	}
+	l.write(l.Stdout, b.String())
+}
+
+func (l *Logger) write(w io.Writer, s string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, _ = io.WriteString(w, s)
+}
+
+// Buffer returns a Logger with l's verbosity that holds its output until
+// flush writes it to l's Stdout and Stderr in one piece. Work running
+// concurrently logs to buffers so each one's messages stay together.
+func (l *Logger) Buffer() (buffered *Logger, flush func()) {
+	b := &block{}
+	buffered = &Logger{Verbose: l.Verbose, Stdout: blockWriter{b, false}, Stderr: blockWriter{b, true}}
+	return buffered, func() {
+		b.mu.Lock()
+		parts := b.parts
+		b.parts = nil
+		b.mu.Unlock()
+
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		for _, p := range parts {
+			w := l.Stdout
+			if p.stderr {
+				w = l.Stderr
+			}
+			_, _ = w.Write(p.data)
+		}
+	}
+}
+
+// block is the output held by a buffered Logger, in order, with the stream
+// each part was written to.
+type block struct {
+	mu    sync.Mutex
+	parts []blockPart
+}
+
+type blockPart struct {
+	stderr bool
+	data   []byte
+}
+
+type blockWriter struct {
+	b      *block
+	stderr bool
+}
+
+func (w blockWriter) Write(p []byte) (int, error) {
+	w.b.mu.Lock()
+	defer w.b.mu.Unlock()
+	if n := len(w.b.parts); n > 0 && w.b.parts[n-1].stderr == w.stderr {
+		w.b.parts[n-1].data = append(w.b.parts[n-1].data, p...)
+	} else {
+		w.b.parts = append(w.b.parts, blockPart{stderr: w.stderr, data: append([]byte(nil), p...)})
+	}
+	return len(p), nil
 }
@@ -71,3 +71,44 @@ func TestVerboseEnabled(t *testing.T) {
 		t.Errorf("expected output to contain 'detail', got %q", out.String())
 	}
 }
+
+func TestDiffColorsLines(t *testing.T) {
+	var out bytes.Buffer
+	l := &Logger{Stdout: &out, Stderr: &bytes.Buffer{}}
+	l.Diff("--- a/x\n+++ b/x\n@@ -1 +1 @@\n-old\n+new\n")
+	got := out.String()
+	if !strings.Contains(got, colorRed+"-old"+colorReset) {
+		t.Errorf("expected removed line in red, got %q", got)
+	}
+	if !strings.Contains(got, colorGreen+"+new"+colorReset) {
+		t.Errorf("expected added line in green, got %q", got)
+	}
+	if !strings.Contains(got, "--- a/x\n") {
+		t.Errorf("expected file header uncolored, got %q", got)
+	}
+}
+
+func TestBufferHoldsOutputUntilFlush(t *testing.T) {
+	var out, errOut bytes.Buffer
+	l := &Logger{Stdout: &out, Stderr: &errOut}
+	b, flush := l.Buffer()
+	b.Info("one")
+	b.Warning("two")
+	b.Info("three")
+	l.Info("direct")
+	if strings.Contains(out.String(), "one") || errOut.Len() != 0 {
+		t.Fatalf("expected buffered output to be held, got %q %q", out.String(), errOut.String())
+	}
+
+	flush()
+	got := out.String()
+	if !strings.Contains(got, "direct") || !strings.Contains(got, "one") || !strings.Contains(got, "three") {
+		t.Errorf("expected the buffered messages after flush, got %q", got)
+	}
+	if strings.Index(got, "one") > strings.Index(got, "three") {
+		t.Errorf("expected buffered messages in order, got %q", got)
+	}
+	if !strings.Contains(errOut.String(), "two") {
+		t.Errorf("expected the warning on stderr, got %q", errOut.String())
+	}
+}
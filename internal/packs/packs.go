@@ -0,0 +1,213 @@
+// Package packs resolves template pack sources — local directories and git
+// repositories — into directories on disk, caching git checkouts.
+package packs
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/joescharf/gsi/internal/templates"
+)
+
+// Source is a parsed pack reference: a local directory, or a git repository
+// with an optional ref (branch, tag or commit).
+type Source struct {
+	Location string // directory path or git URL
+	Ref      string // git ref requested with @ref; empty means the default branch
+	Git      bool
+}
+
+// ParseSource parses a --pack value: a directory path, or a git URL
+// (https://, ssh://, git://, file://, user@host:path, a path ending in .git,
+// or a bare repository) optionally followed by @ref.
+func ParseSource(raw string) (Source, error) {
+	if raw == "" {
+		return Source{}, fmt.Errorf("empty pack source")
+	}
+	loc, ref := raw, ""
+	// The ref follows the last @ after the last /, which keeps the user in
+	// git@host:org/repo.git out of it; a ref never contains ':'.
+	if at := strings.LastIndex(raw, "@"); at > strings.LastIndex(raw, "/") {
+		if r := raw[at+1:]; !strings.Contains(r, ":") {
+			loc, ref = raw[:at], r
+		}
+	}
+	if loc == "" {
+		return Source{}, fmt.Errorf("invalid pack source %q", raw)
+	}
+	src := Source{Location: loc, Ref: ref}
+	src.Git = ref != "" || isGitURL(loc) || isBareRepo(loc)
+	if !src.Git {
+		if rest, ok := strings.CutPrefix(loc, "~/"); ok {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return Source{}, fmt.Errorf("resolving pack %s: %w", raw, err)
+			}
+			loc = filepath.Join(home, rest)
+		}
+		abs, err := filepath.Abs(loc)
+		if err != nil {
+			return Source{}, fmt.Errorf("resolving pack %s: %w", raw, err)
+		}
+		src.Location = abs
+	}
+	return src, nil
+}
+
+// String formats s the way ParseSource accepts it.
+func (s Source) String() string {
+	if s.Ref == "" {
+		return s.Location
+	}
+	return s.Location + "@" + s.Ref
+}
+
+func isGitURL(loc string) bool {
+	if strings.Contains(loc, "://") || strings.HasSuffix(loc, ".git") {
+		return true
+	}
+	// scp-like user@host:path
+	at, colon := strings.Index(loc, "@"), strings.Index(loc, ":")
+	return at > 0 && colon > at && !strings.Contains(loc[:colon], "/")
+}
+
+func isBareRepo(dir string) bool {
+	for _, name := range []string{"HEAD", "objects", "refs"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// Loaded is a pack resolved to a directory.
+type Loaded struct {
+	Source Source
+	Dir    string          // directory holding the pack's templates
+	Commit string          // checked-out commit for git packs; "" otherwise
+	Pack   *templates.Pack // the pack's manifest; nil when it only overrides templates
+}
+
+// Fetcher resolves pack sources, keeping git checkouts under CacheDir.
+type Fetcher struct {
+	CacheDir string
+	Offline  bool // use cached checkouts as they are; never clone or fetch
+}
+
+// Load resolves raw to a directory and reads its pack manifest. Git packs are
+// cloned into the cache on first use and fetched on later ones, then checked
+// out at the requested ref. Offline, a git pack must already be cached.
+func (f *Fetcher) Load(raw string) (*Loaded, error) {
+	src, err := ParseSource(raw)
+	if err != nil {
+		return nil, err
+	}
+	l := &Loaded{Source: src, Dir: src.Location}
+	if src.Git {
+		if l.Dir, l.Commit, err = f.checkout(src); err != nil {
+			return nil, fmt.Errorf("pack %s: %w", raw, err)
+		}
+	} else if info, err := os.Stat(src.Location); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("pack %s: not a directory or git repository", raw)
+	}
+	if l.Pack, err = templates.LoadPack(l.Dir); err != nil {
+		return nil, fmt.Errorf("pack %s: %w", raw, err)
+	}
+	return l, nil
+}
+
+// CachePath returns the cache directory for a git source. Each location and
+// ref gets its own checkout so two refs of one repository can be layered.
+func (f *Fetcher) CachePath(src Source) string {
+	sum := sha256.Sum256([]byte(src.String()))
+	name := strings.TrimSuffix(filepath.Base(strings.TrimRight(src.Location, "/")), ".git")
+	name = strings.Map(func(r rune) rune {
+		if r == ':' || r == '@' || r == '\\' {
+			return '-'
+		}
+		return r
+	}, name)
+	return filepath.Join(f.CacheDir, name+"-"+hex.EncodeToString(sum[:6]))
+}
+
+// checkout clones or fetches src into the cache and checks out its ref,
+// returning the checkout directory and commit. Offline, the ref resolves
+// against whatever was fetched last.
+func (f *Fetcher) checkout(src Source) (dir, commit string, err error) {
+	if f.CacheDir == "" {
+		return "", "", fmt.Errorf("no pack cache directory")
+	}
+	dir = f.CachePath(src)
+	_, statErr := os.Stat(filepath.Join(dir, ".git"))
+	switch {
+	case statErr != nil && f.Offline:
+		return "", "", fmt.Errorf("not cached; run 'gsi cache warm --pack %s' before using it --offline", src)
+	case statErr != nil:
+		if err := os.MkdirAll(f.CacheDir, 0o755); err != nil {
+			return "", "", fmt.Errorf("creating pack cache: %w", err)
+		}
+		_ = os.RemoveAll(dir)
+		if _, err := git("", "clone", "--quiet", "--no-checkout", src.Location, dir); err != nil {
+			_ = os.RemoveAll(dir)
+			return "", "", err
+		}
+	case !f.Offline:
+		if _, err := git(dir, "fetch", "--quiet", "--tags", "--force", "--prune", "origin"); err != nil {
+			return "", "", err
+		}
+	}
+
+	commit, err = resolveRef(dir, src.Ref)
+	if err != nil {
+		return "", "", err
+	}
+	if _, err := git(dir, "checkout", "--quiet", "--force", "--detach", commit); err != nil {
+		return "", "", err
+	}
+	return dir, commit, nil
+}
+
+// resolveRef returns the commit for ref in the clone at dir, preferring the
+// remote branch so a fetched branch moves forward, then tags and commits.
+func resolveRef(dir, ref string) (string, error) {
+	candidates := []string{"refs/remotes/origin/HEAD"}
+	if ref != "" {
+		candidates = []string{"refs/remotes/origin/" + ref, "refs/tags/" + ref, ref}
+	}
+	for _, c := range candidates {
+		if out, err := git(dir, "rev-parse", "--verify", "--quiet", c+"^{commit}"); err == nil {
+			return out, nil
+		}
+	}
+	if ref == "" {
+		return "", fmt.Errorf("cannot determine the default branch")
+	}
+	return "", fmt.Errorf("unknown ref %q", ref)
+}
+
+// git runs a git command in dir and returns its trimmed stdout. Prompts for
+// credentials are disabled so a private repository fails instead of hanging.
+func git(dir string, args ...string) (string, error) {
+	sub := args[0]
+	if dir != "" {
+		args = append([]string{"-C", dir}, args...)
+	}
+	cmd := exec.Command("git", args...)
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return "", fmt.Errorf("git %s: %s", sub, msg)
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
@@ -0,0 +1,166 @@
+package packs
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseSource(t *testing.T) {
+	tests := []struct {
+		raw      string
+		location string
+		ref      string
+		git      bool
+	}{
+		{"https://github.com/acme/pack.git", "https://github.com/acme/pack.git", "", true},
+		{"https://github.com/acme/pack.git@v1.2.0", "https://github.com/acme/pack.git", "v1.2.0", true},
+		{"git@github.com:acme/pack.git", "git@github.com:acme/pack.git", "", true},
+		{"git@github.com:acme/pack.git@main", "git@github.com:acme/pack.git", "main", true},
+		{"file:///srv/packs/company", "file:///srv/packs/company", "", true},
+		{"file:///srv/packs/company@abc123", "file:///srv/packs/company", "abc123", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			src, err := ParseSource(tt.raw)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if src.Location != tt.location || src.Ref != tt.ref || src.Git != tt.git {
+				t.Errorf("ParseSource(%q) = %+v", tt.raw, src)
+			}
+		})
+	}
+
+	dir := t.TempDir()
+	src, err := ParseSource(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if src.Git || src.Location != dir {
+		t.Errorf("local directory parsed as %+v", src)
+	}
+	if _, err := ParseSource(""); err == nil {
+		t.Error("expected error for empty source")
+	}
+}
+
+func TestLoadLocalDirectory(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "dockerfile.tmpl"), "FROM acme/base\n")
+	writeFile(t, filepath.Join(dir, "pack.yaml"), "name: acme\nfiles:\n  - {name: lint, path: .acme-lint.yml, template: lint.tmpl}\n")
+
+	l, err := (&Fetcher{}).Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.Dir != dir || l.Commit != "" {
+		t.Errorf("Load = %+v", l)
+	}
+	if l.Pack == nil || l.Pack.Name != "acme" || len(l.Pack.Files) != 1 {
+		t.Errorf("expected the pack manifest, got %+v", l.Pack)
+	}
+
+	if _, err := (&Fetcher{}).Load(filepath.Join(dir, "missing")); err == nil {
+		t.Error("expected error for a missing directory")
+	}
+}
+
+func TestLoadGitPack(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	bare := bareRepo(t)
+	cache := t.TempDir()
+	f := &Fetcher{CacheDir: cache}
+
+	for _, raw := range []string{bare, "file://" + bare} {
+		l, err := f.Load(raw)
+		if err != nil {
+			t.Fatalf("Load(%s): %v", raw, err)
+		}
+		if !strings.HasPrefix(l.Dir, cache) || len(l.Commit) != 40 {
+			t.Errorf("Load(%s) = %+v", raw, l)
+		}
+		got, _ := os.ReadFile(filepath.Join(l.Dir, "dockerfile.tmpl"))
+		if string(got) != "FROM acme/base:2\n" {
+			t.Errorf("default branch content = %q", got)
+		}
+	}
+
+	l, err := f.Load(bare + "@v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := os.ReadFile(filepath.Join(l.Dir, "dockerfile.tmpl"))
+	if string(got) != "FROM acme/base:1\n" {
+		t.Errorf("v1 content = %q", got)
+	}
+
+	// A second load reuses the cached clone
+	again, err := f.Load(bare + "@v1")
+	if err != nil || again.Dir != l.Dir || again.Commit != l.Commit {
+		t.Errorf("reload = %+v, %v", again, err)
+	}
+
+	if _, err := f.Load(bare + "@nope"); err == nil || !strings.Contains(err.Error(), "unknown ref") {
+		t.Errorf("expected unknown ref error, got %v", err)
+	}
+}
+
+// bareRepo creates a bare repository whose default branch has two commits,
+// the first tagged v1.
+func bareRepo(t *testing.T) string {
+	t.Helper()
+	work := t.TempDir()
+	run := func(dir string, args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@example.com",
+			"GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@example.com")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	run(work, "init", "--quiet", "--initial-branch=main")
+	writeFile(t, filepath.Join(work, "dockerfile.tmpl"), "FROM acme/base:1\n")
+	run(work, "add", ".")
+	run(work, "commit", "--quiet", "-m", "v1")
+	run(work, "tag", "v1")
+	writeFile(t, filepath.Join(work, "dockerfile.tmpl"), "FROM acme/base:2\n")
+	run(work, "commit", "--quiet", "-am", "v2")
+
+	bare := filepath.Join(t.TempDir(), "company-pack")
+	run(work, "clone", "--quiet", "--bare", work, bare)
+	return bare
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadGitPackOffline(t *testing.T) {
+	bare := bareRepo(t)
+	cache := t.TempDir()
+
+	offline := &Fetcher{CacheDir: cache, Offline: true}
+	if _, err := offline.Load(bare); err == nil || !strings.Contains(err.Error(), "not cached") {
+		t.Fatalf("expected a not-cached error, got %v", err)
+	}
+
+	online, err := (&Fetcher{CacheDir: cache}).Load(bare + "@v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := offline.Load(bare + "@v1")
+	if err != nil || l.Dir != online.Dir || l.Commit != online.Commit {
+		t.Errorf("offline load = %+v, %v; want the cached checkout %s", l, err, online.Dir)
+	}
+}
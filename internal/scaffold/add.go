@@ -0,0 +1,240 @@
+package scaffold
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// capabilityMarkers maps each capability to a path whose presence indicates
+// the capability is already part of a project. Release is missing: its files
+// depend on the CI provider (see releaseMarkers).
+var capabilityMarkers = map[string]string{
+	CapBmad:         "_bmad",
+	CapConfig:       filepath.Join("internal", "config", "config.go"),
+	CapGit:          ".git",
+	CapDocs:         filepath.Join("docs", "mkdocs.yml"),
+	CapUI:           "ui",
+	CapGoreleaser:   ".goreleaser.yml",
+	CapDocker:       "Dockerfile",
+	CapMockery:      ".mockery.yml",
+	CapEditorconfig: ".editorconfig",
+	CapMakefile:     "Makefile",
+}
+
+// releaseMarkers maps each CI provider to the release pipeline file whose
+// presence indicates the release capability. Without CI there is none.
+var releaseMarkers = map[string]string{
+	CIGitHub: filepath.Join(".github", "workflows", "release.yml"),
+	CIGitLab: filepath.Join(".gitlab", "ci", "release.yml"),
+	CIGitea:  filepath.Join(".gitea", "workflows", "release.yml"),
+}
+
+// DetectCapabilities inspects an existing project directory and reports which
+// capabilities are already present, looking for the release pipeline of the
+// given CI provider.
+func DetectCapabilities(dir, ci string) map[string]bool {
+	caps := make(map[string]bool, len(capabilityMarkers)+1)
+	exists := func(marker string) bool {
+		_, err := os.Stat(filepath.Join(dir, marker))
+		return err == nil
+	}
+	for name, marker := range capabilityMarkers {
+		caps[name] = exists(marker)
+	}
+	marker, ok := releaseMarkers[ci]
+	caps[CapRelease] = ok && exists(marker)
+	return caps
+}
+
+// existingCapabilities returns the capabilities the project in
+// Config.ProjectDir already has: those recorded in its manifest, with the
+// ones the manifest does not mention detected from the files on disk.
+func (s *Scaffolder) existingCapabilities() map[string]bool {
+	caps := DetectCapabilities(s.Config.ProjectDir, s.ciProvider())
+	if s.manifest != nil {
+		for name, on := range s.manifest.Capabilities {
+			if IsCapability(name) {
+				caps[name] = on
+			}
+		}
+	}
+	return caps
+}
+
+var majorVersionSuffix = regexp.MustCompile(`^v[0-9]+$`)
+
+// ReadModulePath returns the module path declared in dir/go.mod.
+func ReadModulePath(dir string) (string, error) {
+	f, err := os.Open(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return "", fmt.Errorf("reading go.mod: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		rest, ok := strings.CutPrefix(line, "module")
+		if !ok || (rest != "" && rest[0] != ' ' && rest[0] != '\t') {
+			continue
+		}
+		if i := strings.Index(rest, "//"); i >= 0 {
+			rest = rest[:i]
+		}
+		mod := strings.TrimSpace(rest)
+		if unquoted, err := strconv.Unquote(mod); err == nil {
+			mod = unquoted
+		}
+		if mod == "" {
+			break
+		}
+		return mod, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("reading go.mod: %w", err)
+	}
+	return "", fmt.Errorf("no module directive found in %s", filepath.Join(dir, "go.mod"))
+}
+
+// selectCapabilities keeps the steps owned by the requested capabilities,
+// plus core steps that run after one of them so follow-up work such as
+// go mod tidy still happens. Everything else is dropped from the plan, and
+// from the After lists of the steps kept, so none waits for a step that
+// never runs.
+func selectCapabilities(plan []PlannedStep, requested map[string]bool) []PlannedStep {
+	selected := make(map[string]bool)
+	var out []PlannedStep
+	for _, p := range plan {
+		keep := requested[p.Capability]
+		if p.Capability == "" {
+			for _, dep := range p.After {
+				if selected[dep] {
+					keep = true
+					break
+				}
+			}
+		}
+		if keep {
+			selected[p.Name] = true
+			p.After = slices.DeleteFunc(slices.Clone(p.After), func(dep string) bool { return !selected[dep] })
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// ProjectNameFromModule derives the project name from a module path, skipping
+// a trailing major version element (e.g., "github.com/acme/app/v2" -> "app").
+func ProjectNameFromModule(modulePath string) string {
+	name := path.Base(modulePath)
+	if majorVersionSuffix.MatchString(name) {
+		name = path.Base(path.Dir(modulePath))
+	}
+	return name
+}
+
+// Add runs only the steps belonging to the named capabilities against the
+// existing Go module in Config.ProjectDir. The module path and project name
+// are read from go.mod rather than derived from the directory name. Like Run,
+// it rolls the directory back when a step fails.
+func (s *Scaffolder) Add(names []string) (err error) {
+	cfg := &s.Config
+
+	if len(names) == 0 {
+		return fmt.Errorf("at least one capability is required")
+	}
+	requested := make(map[string]bool, len(names))
+	for _, name := range names {
+		if !IsCapability(name) {
+			return fmt.Errorf("unknown capability %q (available: %s)", name, strings.Join(CapabilityNames(), ", "))
+		}
+		requested[name] = true
+	}
+
+	if cfg.ProjectDir == "" {
+		dir, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("getting current directory: %w", err)
+		}
+		cfg.ProjectDir = dir
+	}
+	s.Executor.Dir = cfg.ProjectDir
+
+	modulePath, err := ReadModulePath(cfg.ProjectDir)
+	if err != nil {
+		return fmt.Errorf("%s is not a Go module: %w", cfg.ProjectDir, err)
+	}
+	cfg.GoModulePath = modulePath
+	cfg.ProjectName = ProjectNameFromModule(modulePath)
+
+	// Keep the identity recorded when the project was scaffolded
+	s.loadManifest()
+	if s.manifest != nil {
+		s.manifest.Project.applyTo(cfg)
+	}
+	if err := s.resolveProjectDefaults(); err != nil {
+		return err
+	}
+	s.usePacksFrom(s.manifest)
+	if err := s.loadPacks(); err != nil {
+		return err
+	}
+
+	// Start from what the project already has so templates see the real
+	// capability set, then switch on the requested ones.
+	cfg.Capabilities = s.existingCapabilities()
+	for name := range requested {
+		cfg.Capabilities[name] = true
+	}
+	if err := s.validateIdentifiers(); err != nil {
+		return err
+	}
+
+	s.Logger.Plain("")
+	s.Logger.Info("Adding capabilities to existing project:")
+	s.Logger.Plain("  Project Name:  " + cfg.ProjectName)
+	s.Logger.Plain("  Project Dir:   " + cfg.ProjectDir)
+	s.Logger.Plain("  Module Path:   " + cfg.GoModulePath)
+	s.Logger.Plain("  Adding:        " + strings.Join(names, ", "))
+	s.printPacks()
+	if cfg.DryRun {
+		s.Logger.Plain("  \033[1;33mMode:          DRY-RUN\033[0m")
+	}
+	s.Logger.Plain("")
+
+	if err := ValidateEnvironment(cfg, s.Logger); err != nil {
+		return err
+	}
+	for name := range requested {
+		if !cfg.IsEnabled(name) {
+			return fmt.Errorf("cannot add %s: a required tool is missing", name)
+		}
+	}
+	if requested[CapUI] && !CheckCommand("bun") {
+		return fmt.Errorf("bun is required to add the ui capability")
+	}
+
+	plan, err := s.Plan()
+	if err != nil {
+		return err
+	}
+	defer s.endJournal(&err)
+	if err := s.beginJournal(); err != nil {
+		return err
+	}
+	defer s.openRunLog(cfg.ProjectName)()
+	if err := s.execute(selectCapabilities(plan, requested)); err != nil {
+		return err
+	}
+
+	s.Logger.Plain("")
+	s.Logger.Success("Added " + strings.Join(names, ", "))
+	return nil
+}
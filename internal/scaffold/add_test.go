@@ -0,0 +1,180 @@
+package scaffold
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeGoMod(t *testing.T, dir, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReadModulePath(t *testing.T) {
+	tests := []struct {
+		content string
+		want    string
+	}{
+		{"module github.com/acme/app\n\ngo 1.22\n", "github.com/acme/app"},
+		{"// comment\nmodule   gitlab.com/group/sub/app // trailing\n", "gitlab.com/group/sub/app"},
+		{"module \"go.example.com/app\"\n", "go.example.com/app"},
+	}
+	for _, tt := range tests {
+		dir := t.TempDir()
+		writeGoMod(t, dir, tt.content)
+		got, err := ReadModulePath(dir)
+		if err != nil {
+			t.Fatalf("ReadModulePath(%q) failed: %v", tt.content, err)
+		}
+		if got != tt.want {
+			t.Errorf("ReadModulePath(%q) = %q, want %q", tt.content, got, tt.want)
+		}
+	}
+}
+
+func TestReadModulePathMissing(t *testing.T) {
+	if _, err := ReadModulePath(t.TempDir()); err == nil {
+		t.Error("expected error when go.mod is missing")
+	}
+
+	dir := t.TempDir()
+	writeGoMod(t, dir, "go 1.22\n")
+	if _, err := ReadModulePath(dir); err == nil {
+		t.Error("expected error when module directive is missing")
+	}
+}
+
+func TestProjectNameFromModule(t *testing.T) {
+	tests := map[string]string{
+		"github.com/acme/app":    "app",
+		"github.com/acme/app/v2": "app",
+		"go.example.com/tool":    "tool",
+		"app":                    "app",
+	}
+	for mod, want := range tests {
+		if got := ProjectNameFromModule(mod); got != want {
+			t.Errorf("ProjectNameFromModule(%q) = %q, want %q", mod, got, want)
+		}
+	}
+}
+
+func TestDetectCapabilities(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "Dockerfile"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "ui"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	caps := DetectCapabilities(dir, CIGitHub)
+	if !caps[CapDocker] || !caps[CapUI] {
+		t.Errorf("expected docker and ui detected, got %v", caps)
+	}
+	if caps[CapDocs] || caps[CapMakefile] {
+		t.Errorf("expected docs and makefile not detected, got %v", caps)
+	}
+	if len(caps) != len(DefaultCapabilities()) {
+		t.Errorf("expected an entry for every capability, got %v", caps)
+	}
+}
+
+func TestDetectCapabilitiesReleaseByCI(t *testing.T) {
+	dir := t.TempDir()
+	marker := filepath.Join(dir, ".gitlab", "ci", "release.yml")
+	if err := os.MkdirAll(filepath.Dir(marker), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(marker, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for ci, want := range map[string]bool{CIGitLab: true, CIGitHub: false, CIGitea: false, CINone: false} {
+		if got := DetectCapabilities(dir, ci)[CapRelease]; got != want {
+			t.Errorf("release detected under %s = %v, want %v", ci, got, want)
+		}
+	}
+}
+
+func TestExistingCapabilitiesFromManifest(t *testing.T) {
+	s, _, _ := testScaffolder(t, false)
+	if err := os.WriteFile(filepath.Join(s.Config.ProjectDir, "Dockerfile"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	s.Config.CI = CINone
+	s.manifest = &Manifest{Capabilities: map[string]bool{CapRelease: true, CapDocker: false}}
+
+	caps := s.existingCapabilities()
+	if !caps[CapRelease] || caps[CapDocker] {
+		t.Errorf("expected the manifest's release and docker settings, got %v", caps)
+	}
+	if len(caps) != len(DefaultCapabilities()) {
+		t.Errorf("expected an entry for every capability, got %v", caps)
+	}
+}
+
+func TestAddDocker(t *testing.T) {
+	s, stdout, _ := testScaffolder(t, false)
+	writeGoMod(t, s.Config.ProjectDir, "module gitlab.com/acme/widget\n")
+
+	if err := s.Add([]string{CapDocker}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if s.Config.GoModulePath != "gitlab.com/acme/widget" || s.Config.ProjectName != "widget" {
+		t.Errorf("expected module and name from go.mod, got %q / %q", s.Config.GoModulePath, s.Config.ProjectName)
+	}
+	for _, f := range []string{"Dockerfile", ".dockerignore"} {
+		if _, err := os.Stat(filepath.Join(s.Config.ProjectDir, f)); err != nil {
+			t.Errorf("expected %s to be created", f)
+		}
+	}
+	// Steps for other capabilities must not run
+	if _, err := os.Stat(filepath.Join(s.Config.ProjectDir, ".goreleaser.yml")); err == nil {
+		t.Error("expected .goreleaser.yml not to be created")
+	}
+
+	content, _ := os.ReadFile(filepath.Join(s.Config.ProjectDir, "Dockerfile"))
+	if !strings.Contains(string(content), "widget") {
+		t.Errorf("expected project name from go.mod in Dockerfile, got %q", content)
+	}
+	if !strings.Contains(stdout.String(), "Added docker") {
+		t.Errorf("expected completion message, got %q", stdout.String())
+	}
+}
+
+func TestAddConcurrent(t *testing.T) {
+	s, _, _ := testScaffolder(t, false)
+	s.Config.Jobs = DefaultJobs
+	writeGoMod(t, s.Config.ProjectDir, "module github.com/acme/widget\n")
+
+	if err := s.Add([]string{CapDocker}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	for _, f := range []string{"Dockerfile", ".dockerignore", ManifestName} {
+		if _, err := os.Stat(filepath.Join(s.Config.ProjectDir, f)); err != nil {
+			t.Errorf("expected %s to be created", f)
+		}
+	}
+}
+
+func TestAddUnknownCapability(t *testing.T) {
+	s, _, _ := testScaffolder(t, false)
+	writeGoMod(t, s.Config.ProjectDir, "module github.com/acme/widget\n")
+
+	err := s.Add([]string{"dockr"})
+	if err == nil || !strings.Contains(err.Error(), "unknown capability") {
+		t.Errorf("expected unknown capability error, got %v", err)
+	}
+}
+
+func TestAddRequiresGoMod(t *testing.T) {
+	s, _, _ := testScaffolder(t, false)
+
+	if err := s.Add([]string{CapDocker}); err == nil {
+		t.Error("expected error when go.mod is missing")
+	}
+}
@@ -0,0 +1,342 @@
+package scaffold
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/joescharf/gsi/internal/logger"
+)
+
+// Artifacts in the offline cache. Each stands in for the network access of
+// one scaffold step under --offline and is filled by 'gsi cache warm'.
+const (
+	ArtifactGoModules = "go-modules" // module cache that go mod tidy uses as its GOPROXY
+	ArtifactBmad      = "bmad"       // files written by npx bmad-method install
+	ArtifactDocs      = "docs"       // docs pyproject.toml and uv.lock, with the uv cache in uv/
+	ArtifactUI        = "ui"         // the ui/ skeleton from bun init, node_modules included
+)
+
+// Artifacts lists every offline artifact in the order 'gsi cache warm' fills
+// them.
+var Artifacts = []string{ArtifactGoModules, ArtifactBmad, ArtifactDocs, ArtifactUI}
+
+// warmProject names the throwaway project 'gsi cache warm' scaffolds; cached
+// files that mention it are rewritten for the real project on restore.
+const warmProject = "gsi-cache-warm"
+
+// networkSteps lists the steps that reach the network, the capability that
+// owns each (empty for core steps), the artifact that replaces the network
+// under --offline, and a path whose presence means the step has nothing to do.
+var networkSteps = []struct {
+	Step, Capability, Artifact, Done string
+}{
+	{"bmad", CapBmad, ArtifactBmad, "_bmad"},
+	{"go-mod-tidy", "", ArtifactGoModules, ""},
+	{"docs", CapDocs, ArtifactDocs, filepath.Join("docs", "pyproject.toml")},
+	{"ui", CapUI, ArtifactUI, "ui"},
+}
+
+// ArtifactCache is the directory holding the offline artifacts, one
+// subdirectory each. An artifact is moved into place only once it is
+// complete, so a present directory is always usable.
+type ArtifactCache struct {
+	Dir string
+}
+
+// Path returns the directory of the named artifact.
+func (c ArtifactCache) Path(artifact string) string {
+	return filepath.Join(c.Dir, artifact)
+}
+
+// Has reports whether the named artifact has been cached.
+func (c ArtifactCache) Has(artifact string) bool {
+	info, err := os.Stat(c.Path(artifact))
+	return err == nil && info.IsDir()
+}
+
+func (s *Scaffolder) artifactCache() ArtifactCache {
+	return ArtifactCache{Dir: s.Config.ArtifactDir}
+}
+
+// validateOffline fails when a step that needs the network would run and its
+// artifact is not cached, listing every such step.
+func validateOffline(cfg *Config, log *logger.Logger) error {
+	cache := ArtifactCache{Dir: cfg.ArtifactDir}
+	var cold []string
+	for _, n := range networkSteps {
+		if cfg.OnlyDocs && n.Step != "docs" {
+			continue
+		}
+		if n.Capability != "" && !cfg.IsEnabled(n.Capability) {
+			continue
+		}
+		if n.Done != "" {
+			if _, err := os.Stat(filepath.Join(cfg.ProjectDir, n.Done)); err == nil {
+				continue
+			}
+		}
+		if cache.Has(n.Artifact) {
+			log.VerboseMsg("Found cached " + n.Artifact + " for " + n.Step)
+			continue
+		}
+		cold = append(cold, n.Step)
+		log.Error(fmt.Sprintf("%s needs the network: %s is not cached", n.Step, cache.Path(n.Artifact)))
+	}
+	if len(cold) == 0 {
+		return nil
+	}
+	log.Error("Run 'gsi cache warm' while online, or disable these steps (e.g., --no-bmad)")
+	return fmt.Errorf("--offline: no cached artifacts for %s", strings.Join(cold, ", "))
+}
+
+// restoreArtifact copies the cached artifact into dest, keeping files that
+// already exist there.
+func (s *Scaffolder) restoreArtifact(artifact, dest, description string) error {
+	src := s.artifactCache().Path(artifact)
+	s.Logger.Info(description)
+	s.Logger.VerboseMsg("Copying " + src + " to " + dest)
+	if s.Config.DryRun {
+		s.Logger.Warning(fmt.Sprintf("[DRY-RUN] Would copy %s to %s", src, dest))
+		return nil
+	}
+	if err := copyTree(src, dest); err != nil {
+		s.Logger.Error(description + " - Failed")
+		return fmt.Errorf("%s: %w", description, err)
+	}
+	s.Logger.Success(description + " - Done")
+	return nil
+}
+
+// docsProjectName returns the name of the docs uv project of projectName.
+// It is already PEP 503 normalised, so uv.lock spells it the same way.
+func docsProjectName(projectName string) string {
+	return DeriveIdentifiers(projectName, Repository{}).DocsProjectName()
+}
+
+// restoreDocs writes the cached docs pyproject.toml and uv.lock, renamed for
+// this project, and installs the locked packages from the cached uv cache.
+func (s *Scaffolder) restoreDocs() error {
+	src := s.artifactCache().Path(ArtifactDocs)
+	docsDir := filepath.Join(s.Config.ProjectDir, "docs")
+	s.Logger.Info("Restoring docs/pyproject.toml and uv.lock from the offline cache")
+	if err := s.FS.MkdirAll(docsDir, 0o755); err != nil {
+		return fmt.Errorf("creating docs/: %w", err)
+	}
+	for _, name := range []string{"pyproject.toml", "uv.lock", ".python-version"} {
+		data, err := os.ReadFile(filepath.Join(src, name))
+		if os.IsNotExist(err) && name == ".python-version" {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("reading cached docs project: %w", err)
+		}
+		data = bytes.ReplaceAll(data, []byte(docsProjectName(warmProject)), []byte(docsProjectName(s.Config.ProjectName)))
+		if err := s.FS.WriteFile(filepath.Join(docsDir, name), data, 0o644); err != nil {
+			return err
+		}
+	}
+	sync := Cmd("uv", "sync")
+	sync.Dir, sync.Env = "docs", s.uvEnv()
+	return s.Executor.Execute(sync, "Installing docs dependencies from the offline cache")
+}
+
+// uvEnv returns the environment that points uv at the cached packages
+// under --offline, or nil online.
+func (s *Scaffolder) uvEnv() []string {
+	if !s.Config.Offline {
+		return nil
+	}
+	return []string{"UV_OFFLINE=1", "UV_CACHE_DIR=" + filepath.Join(s.artifactCache().Path(ArtifactDocs), "uv")}
+}
+
+// goEnv returns the environment for go mod tidy: under
+// --offline modules come from the cached module cache served as a file
+// GOPROXY, and while warming the cache they are downloaded into it. The
+// checksum database is off offline; the cached modules were verified
+// against it when they were downloaded.
+func (s *Scaffolder) goEnv() []string {
+	switch {
+	case s.goModCache != "":
+		return []string{"GOMODCACHE=" + s.goModCache, "GOFLAGS=-modcacherw"}
+	case s.Config.Offline:
+		proxy := "file://" + filepath.ToSlash(filepath.Join(s.artifactCache().Path(ArtifactGoModules), "cache", "download"))
+		return []string{"GOPROXY=" + proxy, "GOSUMDB=off"}
+	}
+	return nil
+}
+
+// WarmCache fills the offline cache with the named artifacts (all of them
+// when none are named) by running the network steps in throwaway projects.
+// The Go modules are those needed by a project with every capability on,
+// rendered from Config.Packs.
+func (s *Scaffolder) WarmCache(artifacts []string) error {
+	if len(artifacts) == 0 {
+		artifacts = Artifacts
+	}
+	for _, name := range artifacts {
+		if !slices.Contains(Artifacts, name) {
+			return fmt.Errorf("unknown artifact %q (available: %s)", name, strings.Join(Artifacts, ", "))
+		}
+	}
+
+	cache := s.artifactCache()
+	if err := os.MkdirAll(cache.Dir, 0o755); err != nil {
+		return fmt.Errorf("creating artifact cache: %w", err)
+	}
+	// Work inside the cache so finished artifacts can be renamed into place
+	work, err := os.MkdirTemp(cache.Dir, ".warm-")
+	if err != nil {
+		return fmt.Errorf("creating artifact cache: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(work) }()
+	defer s.openRunLog("cache-warm")()
+
+	for _, name := range artifacts {
+		s.Logger.Info("Warming " + name)
+		out := filepath.Join(work, name)
+		if err := os.MkdirAll(out, 0o755); err != nil {
+			return err
+		}
+		if err := s.warmArtifact(name, work, out); err != nil {
+			return fmt.Errorf("warming %s: %w", name, err)
+		}
+		if err := os.RemoveAll(cache.Path(name)); err != nil {
+			return fmt.Errorf("replacing %s: %w", name, err)
+		}
+		if err := os.Rename(out, cache.Path(name)); err != nil {
+			return fmt.Errorf("saving %s: %w", name, err)
+		}
+		s.Logger.Success("Cached " + name + " in " + cache.Path(name))
+	}
+	return nil
+}
+
+// warmArtifact produces the named artifact in out, using work for scratch
+// projects.
+func (s *Scaffolder) warmArtifact(name, work, out string) error {
+	e := *s.Executor
+	switch name {
+	case ArtifactGoModules:
+		return s.warmGoModules(filepath.Join(work, warmProject), out)
+	case ArtifactBmad:
+		e.Dir = out
+		return e.Execute(bmadInstall, "Installing BMAD method framework")
+	case ArtifactDocs:
+		e.Dir = work
+		env := []string{"UV_CACHE_DIR=" + filepath.Join(out, "uv")}
+		uvInit := Cmd("uv", "init", "--name", docsProjectName(warmProject), "docs-project")
+		uvInit.Env = env
+		if err := e.Execute(uvInit, "Initializing uv project"); err != nil {
+			return err
+		}
+		add := docsDepsAdd
+		add.Dir, add.Env = "docs-project", env
+		if err := e.Execute(add, "Adding mkdocs-material dependencies"); err != nil {
+			return err
+		}
+		for _, f := range []string{"pyproject.toml", "uv.lock", ".python-version"} {
+			err := os.Rename(filepath.Join(work, "docs-project", f), filepath.Join(out, f))
+			if err != nil && !(os.IsNotExist(err) && f == ".python-version") {
+				return err
+			}
+		}
+		return nil
+	case ArtifactUI:
+		e.Dir = work
+		if err := e.Execute(Cmd("bun", "init", "--react=shadcn", "ui-project"), "Initializing React/shadcn/Tailwind UI"); err != nil {
+			return err
+		}
+		if err := os.Remove(out); err != nil {
+			return err
+		}
+		return os.Rename(filepath.Join(work, "ui-project"), out)
+	}
+	return nil
+}
+
+// warmGoModules renders a project with every capability on into dir and
+// tidies it with out as the module cache. Steps that need other artifacts or
+// only make sense for a real project are skipped.
+func (s *Scaffolder) warmGoModules(dir, out string) error {
+	cfg := s.Config
+	cfg.ProjectName = warmProject
+	cfg.ProjectDir = dir
+	cfg.GoModulePath = DefaultModulePath(cfg.ModulePrefix, warmProject)
+	cfg.RepoURL, cfg.CI, cfg.License = "", "", ""
+	cfg.DryRun, cfg.Offline, cfg.OnlyDocs = false, false, false
+	cfg.Capabilities = make(map[string]bool, len(DefaultCapabilities()))
+	for name := range DefaultCapabilities() {
+		cfg.Capabilities[name] = true
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	w := NewScaffolder(cfg)
+	w.Pack, w.goModCache = s.Pack, out
+	w.Logger = &logger.Logger{Verbose: s.Logger.Verbose, Stdout: io.Discard, Stderr: s.Logger.Stderr}
+	if s.Logger.Verbose {
+		w.Logger.Stdout = s.Logger.Stdout
+	}
+	w.Executor.Logger = w.Logger
+	w.Executor.Log, w.Executor.Context = s.Executor.Log, s.Executor.Context
+	if err := w.resolveProjectDefaults(); err != nil {
+		return err
+	}
+	if err := w.loadPacks(); err != nil {
+		return err
+	}
+	plan, err := w.Plan()
+	if err != nil {
+		return err
+	}
+	for i, p := range plan {
+		switch p.Name {
+		case "bmad", "docs", "ui", "manifest", "git-init", "github-pages":
+			plan[i].Skip, plan[i].Reason = true, "cache warm"
+		}
+	}
+	return w.execute(plan)
+}
+
+// copyTree copies the directory src into dst, recreating symlinks and
+// skipping files that already exist in dst.
+func copyTree(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return os.MkdirAll(target, info.Mode().Perm()|0o700)
+		}
+		if _, err := os.Lstat(target); err == nil {
+			return nil
+		}
+		if d.Type()&os.ModeSymlink != 0 {
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(target, data, info.Mode().Perm())
+	})
+}
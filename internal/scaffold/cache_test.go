@@ -0,0 +1,170 @@
+package scaffold
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// offlineScaffolder returns a test scaffolder in --offline mode with an
+// empty artifact cache.
+func offlineScaffolder(t *testing.T, dryRun bool) (*Scaffolder, ArtifactCache) {
+	t.Helper()
+	s, _, _ := testScaffolder(t, dryRun)
+	s.Config.Offline = true
+	s.Config.ArtifactDir = t.TempDir()
+	return s, s.artifactCache()
+}
+
+func TestValidateOffline(t *testing.T) {
+	s, cache := offlineScaffolder(t, false)
+	cfg := &s.Config
+	err := validateOffline(cfg, s.Logger)
+	if err == nil || !strings.Contains(err.Error(), "bmad, go-mod-tidy, docs") || strings.Contains(err.Error(), "ui") {
+		t.Fatalf("expected bmad, go-mod-tidy and docs to be listed, got %v", err)
+	}
+
+	// Disabled capabilities and finished steps need nothing
+	cfg.Disable(CapBmad)
+	if err := os.MkdirAll(filepath.Join(cfg.ProjectDir, "docs"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, filepath.Join(cfg.ProjectDir, "docs", "pyproject.toml"), "")
+	if err := validateOffline(cfg, s.Logger); err == nil || err.Error() != "--offline: no cached artifacts for go-mod-tidy" {
+		t.Fatalf("expected only go-mod-tidy, got %v", err)
+	}
+
+	if err := os.MkdirAll(cache.Path(ArtifactGoModules), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := validateOffline(cfg, s.Logger); err != nil {
+		t.Errorf("expected a warm cache to pass, got %v", err)
+	}
+
+	cfg.OnlyDocs = true
+	cfg.Capabilities[CapBmad] = true
+	if err := os.Remove(filepath.Join(cfg.ProjectDir, "docs", "pyproject.toml")); err != nil {
+		t.Fatal(err)
+	}
+	if err := validateOffline(cfg, s.Logger); err == nil || err.Error() != "--offline: no cached artifacts for docs" {
+		t.Errorf("expected only docs in --only-docs mode, got %v", err)
+	}
+}
+
+func TestStepInstallBmadOffline(t *testing.T) {
+	s, cache := offlineScaffolder(t, false)
+	src := cache.Path(ArtifactBmad)
+	for _, dir := range []string{"_bmad", ".claude"} {
+		if err := os.MkdirAll(filepath.Join(src, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeTestFile(t, filepath.Join(src, "_bmad", "config.yaml"), "cached\n")
+	writeTestFile(t, filepath.Join(src, ".claude", "settings.json"), "cached\n")
+	if err := os.Symlink("config.yaml", filepath.Join(src, "_bmad", "link.yaml")); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := s.Config.ProjectDir
+	if err := os.MkdirAll(filepath.Join(dir, ".claude"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, filepath.Join(dir, ".claude", "settings.json"), "mine\n")
+
+	if err := runStep(t, s, "bmad"); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(filepath.Join(dir, "_bmad", "config.yaml")); string(got) != "cached\n" {
+		t.Errorf("_bmad/config.yaml = %q", got)
+	}
+	if got, _ := os.ReadFile(filepath.Join(dir, "_bmad", "link.yaml")); string(got) != "cached\n" {
+		t.Errorf("symlink not restored: %q", got)
+	}
+	if got, _ := os.ReadFile(filepath.Join(dir, ".claude", "settings.json")); string(got) != "mine\n" {
+		t.Errorf("existing file overwritten: %q", got)
+	}
+	if len(s.Recorder.Commands) != 0 {
+		t.Errorf("expected no commands offline, got %+v", s.Recorder.Commands)
+	}
+}
+
+func TestOfflineCommands(t *testing.T) {
+	s, cache := offlineScaffolder(t, true)
+	s.Config.ProjectName = "my-app"
+	if err := os.MkdirAll(cache.Path(ArtifactDocs), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, filepath.Join(cache.Path(ArtifactDocs), "pyproject.toml"), "[project]\nname = \"gsi-cache-warm-docs\"\n")
+	writeTestFile(t, filepath.Join(cache.Path(ArtifactDocs), "uv.lock"), "[[package]]\nname = \"gsi-cache-warm-docs\"\n")
+
+	if err := runStep(t, s, "go-mod-tidy"); err != nil {
+		t.Fatal(err)
+	}
+	// Called directly: the step needs uv on PATH
+	if err := s.stepInitDocs(); err != nil {
+		t.Fatal(err)
+	}
+	var commands []string
+	for _, c := range s.Recorder.Commands {
+		commands = append(commands, c.Command)
+	}
+	all := strings.Join(commands, "\n")
+	for _, want := range []string{
+		"GOPROXY=file://" + filepath.ToSlash(cache.Path(ArtifactGoModules)) + "/cache/download GOSUMDB=off go mod tidy",
+		"UV_OFFLINE=1 UV_CACHE_DIR=" + filepath.Join(cache.Path(ArtifactDocs), "uv") + " uv sync",
+	} {
+		if !strings.Contains(all, want) {
+			t.Errorf("expected %q among commands:\n%s", want, all)
+		}
+	}
+	if strings.Contains(all, "uv init") || strings.Contains(all, "uv add") {
+		t.Errorf("expected no uv init or uv add offline:\n%s", all)
+	}
+
+	for _, name := range []string{"pyproject.toml", "uv.lock"} {
+		got, err := s.FS.ReadFile(filepath.Join(s.Config.ProjectDir, "docs", name))
+		if err != nil || !strings.Contains(string(got), `name = "my-app-docs"`) {
+			t.Errorf("docs/%s = %q, %v; want it renamed for my-app", name, got, err)
+		}
+	}
+}
+
+func TestPlanOffline(t *testing.T) {
+	s, _ := offlineScaffolder(t, false)
+	s.Config.CI = CIGitHub
+	plan, err := s.Plan()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range plan {
+		switch p.Name {
+		case "github-pages":
+			if !p.Skip || p.Reason != "--offline" {
+				t.Errorf("github-pages: skip=%v reason=%q, want skipped for --offline", p.Skip, p.Reason)
+			}
+		case "bmad":
+			if len(p.Tools) != 0 {
+				t.Errorf("bmad needs %v offline, want no tools", p.Tools)
+			}
+		}
+	}
+}
+
+func TestWarmCacheUnknownArtifact(t *testing.T) {
+	s, cache := offlineScaffolder(t, false)
+	if err := s.WarmCache([]string{"node"}); err == nil || !strings.Contains(err.Error(), "unknown artifact") {
+		t.Errorf("expected an unknown artifact error, got %v", err)
+	}
+	if entries, _ := os.ReadDir(cache.Dir); len(entries) != 0 {
+		t.Errorf("expected nothing written to the cache, got %v", entries)
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	for in, want := range map[string]string{"/a b": "'/a b'", "it's": `'it'\''s'`} {
+		if got := shellQuote(in); got != want {
+			t.Errorf("shellQuote(%q) = %s, want %s", in, got, want)
+		}
+	}
+}
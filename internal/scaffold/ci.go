@@ -0,0 +1,60 @@
+package scaffold
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// CI providers, selected with --ci. Pack files and steps that belong to one
+// provider declare it and are skipped under the others.
+const (
+	CIGitHub = "github" // GitHub Actions: .github/workflows
+	CIGitLab = "gitlab" // GitLab CI: .gitlab-ci.yml
+	CIGitea  = "gitea"  // Gitea and Forgejo Actions: .gitea/workflows
+	CINone   = "none"   // no CI configuration
+)
+
+// CIProviders lists the valid --ci values.
+var CIProviders = []string{CIGitHub, CIGitLab, CIGitea, CINone}
+
+// ParseCI validates a --ci value, returning it lowercased. Empty stays empty,
+// meaning the repository's forge decides (see DefaultCI).
+func ParseCI(s string) (string, error) {
+	if s == "" {
+		return "", nil
+	}
+	for _, p := range CIProviders {
+		if strings.EqualFold(p, s) {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported CI provider %q (supported: %s)", s, strings.Join(CIProviders, ", "))
+}
+
+// IsCIProvider reports whether name is a CI provider other than none.
+func IsCIProvider(name string) bool {
+	return name != CINone && slices.Contains(CIProviders, name)
+}
+
+// DefaultCI returns the CI provider native to repo's forge, or GitHub
+// Actions when the forge is unknown.
+func DefaultCI(repo Repository) string {
+	switch repo.Forge {
+	case ForgeGitLab:
+		return CIGitLab
+	case ForgeGitea:
+		return CIGitea
+	}
+	return CIGitHub
+}
+
+// ciProvider returns the configured CI provider, or the forge's default
+// before resolveProjectDefaults has fixed one.
+func (s *Scaffolder) ciProvider() string {
+	if s.Config.CI != "" {
+		return s.Config.CI
+	}
+	repo, _ := s.repository()
+	return DefaultCI(repo)
+}
@@ -0,0 +1,48 @@
+package scaffold
+
+import "testing"
+
+func TestParseCI(t *testing.T) {
+	for in, want := range map[string]string{"": "", "github": CIGitHub, "GitLab": CIGitLab, "gitea": CIGitea, "none": CINone} {
+		got, err := ParseCI(in)
+		if err != nil || got != want {
+			t.Errorf("ParseCI(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseCI("jenkins"); err == nil {
+		t.Error("expected an error for an unknown provider")
+	}
+}
+
+func TestResolveProjectDefaultsCI(t *testing.T) {
+	tests := []struct {
+		module, repoURL, ci, want string
+	}{
+		{"github.com/example/testproj", "", "", CIGitHub},
+		{"gitlab.acme.dev/group/testproj", "", "", CIGitLab},
+		{"codeberg.org/acme/testproj", "", "", CIGitea},
+		{"go.acme.dev/testproj", "", "", CIGitHub},
+		{"go.acme.dev/testproj", "https://gitlab.com/acme/testproj", "", CIGitLab},
+		{"gitlab.com/group/testproj", "", "none", CINone},
+		{"gitlab.com/group/testproj", "", "GitHub", CIGitHub},
+	}
+	for _, tt := range tests {
+		s, _, _ := testScaffolder(t, false)
+		s.Config.GoModulePath, s.Config.RepoURL, s.Config.CI = tt.module, tt.repoURL, tt.ci
+		if err := s.resolveProjectDefaults(); err != nil {
+			t.Fatal(err)
+		}
+		if s.Config.CI != tt.want {
+			t.Errorf("%s %s --ci %q: resolved %q, want %q", tt.module, tt.repoURL, tt.ci, s.Config.CI, tt.want)
+		}
+		if got := s.templateData().CI; got != tt.want {
+			t.Errorf("%s: template CI = %q, want %q", tt.module, got, tt.want)
+		}
+	}
+
+	s, _, _ := testScaffolder(t, false)
+	s.Config.CI = "jenkins"
+	if err := s.resolveProjectDefaults(); err == nil {
+		t.Error("expected an error for --ci jenkins")
+	}
+}
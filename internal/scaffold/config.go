@@ -37,9 +37,28 @@ type Config struct {
 	ProjectName  string
 	Author       string
 	GoModulePath string
+	ModulePrefix string // used to derive GoModulePath when it is empty
+	RepoURL      string // repository URL; empty means derive it from GoModulePath
 	DryRun       bool
+	Diff         bool // show unified diffs for files that would change
 	Verbose      bool
 	OnlyDocs     bool
+	Description  string         // one-line project description for templates
+	License      string         // SPDX identifier; empty means DefaultLicense
+	Year         int            // copyright year; zero means the current year
+	GoVersion    string         // Go major.minor for templates; empty means detect
+	CI           string         // CI provider (CIGitHub, ...); empty means DefaultCI
+	Profile      string         // name of the capability profile applied, if any
+	OnConflict   ConflictPolicy // what to do with hand-edited existing files
+	Version      string         // gsi version recorded in the project manifest
+	Packs        []string       // template pack sources, highest precedence first
+	TemplateDirs []string       // template override directories, searched before packs
+	PackCacheDir string         // where git packs are cloned
+	Offline      bool           // use ArtifactDir instead of the network
+	ArtifactDir  string         // offline artifacts, filled by 'gsi cache warm'
+	LogDir       string         // where each run's command log is written; empty means none
+	KeepFailed   bool           // leave a failed run's changes in place instead of rolling back
+	Jobs         int            // steps run at once; below 2, one at a time
 	Capabilities map[string]bool
 
 	// Derived — set during validation
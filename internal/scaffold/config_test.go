@@ -77,3 +77,18 @@ func TestDisableNonexistent(t *testing.T) {
 		t.Error("expected nonexistent to be disabled")
 	}
 }
+
+func TestDefaultModulePath(t *testing.T) {
+	tests := []struct {
+		prefix, name, want string
+	}{
+		{"", "myapp", "myapp"},
+		{"gitlab.com/acme", "myapp", "gitlab.com/acme/myapp"},
+		{"go.example.com/", "myapp", "go.example.com/myapp"},
+	}
+	for _, tt := range tests {
+		if got := DefaultModulePath(tt.prefix, tt.name); got != tt.want {
+			t.Errorf("DefaultModulePath(%q, %q) = %q, want %q", tt.prefix, tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,264 @@
+package scaffold
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// ConflictPolicy decides what happens when a generated file already exists
+// with different content.
+type ConflictPolicy string
+
+// Conflict policies.
+const (
+	ConflictSkip      ConflictPolicy = "skip"      // keep the existing file (default)
+	ConflictOverwrite ConflictPolicy = "overwrite" // replace it
+	ConflictBackup    ConflictPolicy = "backup"    // save it as <file>.orig, then replace it
+	ConflictPrompt    ConflictPolicy = "prompt"    // show a diff and ask
+	ConflictMerge     ConflictPolicy = "merge"     // write both versions with conflict markers
+)
+
+// conflictKeep leaves any existing file alone, even one gsi generated and
+// nobody edited. Pack files with overwrite: never use it; it is not an
+// --on-conflict value.
+const conflictKeep ConflictPolicy = "keep"
+
+// ConflictPolicies lists the valid --on-conflict values.
+var ConflictPolicies = []ConflictPolicy{ConflictSkip, ConflictOverwrite, ConflictBackup, ConflictPrompt, ConflictMerge}
+
+// ParseConflictPolicy validates an --on-conflict value. Empty means skip.
+func ParseConflictPolicy(s string) (ConflictPolicy, error) {
+	if s == "" {
+		return ConflictSkip, nil
+	}
+	for _, p := range ConflictPolicies {
+		if string(p) == s {
+			return p, nil
+		}
+	}
+	names := make([]string, len(ConflictPolicies))
+	for i, p := range ConflictPolicies {
+		names[i] = string(p)
+	}
+	return "", fmt.Errorf("invalid --on-conflict value %q (valid: %s)", s, strings.Join(names, ", "))
+}
+
+// BackupSuffix is appended to files saved by the backup policy.
+const BackupSuffix = ".orig"
+
+// stampPrefix identifies the header line gsi adds to generated files. The
+// checksum covers the rest of the file, so an edit anywhere else is detected.
+const stampPrefix = "Scaffolded by gsi. gsi-checksum: "
+
+var stampPattern = regexp.MustCompile(regexp.QuoteMeta(stampPrefix) + `([0-9a-f]{12})`)
+
+// commentStyle returns the line-comment opening and closing strings for a
+// file, or ok=false when the file type has no comment syntax gsi knows.
+func commentStyle(path string) (open, close string, ok bool) {
+	base := filepath.Base(path)
+	switch base {
+	case "Makefile", "Dockerfile", ".dockerignore", ".gitignore", ".editorconfig":
+		return "# ", "", true
+	}
+	switch filepath.Ext(base) {
+	case ".go", ".ts", ".js":
+		return "// ", "", true
+	case ".yml", ".yaml", ".sh", ".py", ".toml", ".ini":
+		return "# ", "", true
+	case ".md", ".html":
+		return "<!-- ", " -->", true
+	case ".css":
+		return "/* ", " */", true
+	}
+	return "", "", false
+}
+
+// contentChecksum returns the short checksum recorded in a stamp.
+func contentChecksum(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+// stampContent inserts the gsi header line into content, after a shebang or
+// doctype line if present. Files with no known comment syntax are unchanged.
+func stampContent(path string, content []byte) []byte {
+	open, close, ok := commentStyle(path)
+	if !ok {
+		return content
+	}
+	line := []byte(open + stampPrefix + contentChecksum(content) + close + "\n")
+	if open == "// " {
+		// Keep the stamp from becoming a Go package doc comment
+		line = append(line, '\n')
+	}
+
+	at := 0
+	if bytes.HasPrefix(content, []byte("#!")) || bytes.HasPrefix(bytes.ToUpper(content), []byte("<!DOCTYPE")) {
+		if i := bytes.IndexByte(content, '\n'); i >= 0 {
+			at = i + 1
+		}
+	}
+	out := make([]byte, 0, len(content)+len(line))
+	out = append(out, content[:at]...)
+	out = append(out, line...)
+	return append(out, content[at:]...)
+}
+
+// unstampContent removes the gsi header line, returning the remaining content
+// and the checksum it recorded. found is false when there is no stamp.
+func unstampContent(content []byte) (body []byte, checksum string, found bool) {
+	lines := bytes.SplitAfter(content, []byte("\n"))
+	// The stamp is on the first line, or the second after a shebang/doctype
+	for i := 0; i < len(lines) && i < 2; i++ {
+		m := stampPattern.FindSubmatch(lines[i])
+		if m == nil {
+			continue
+		}
+		rest := lines[i+1:]
+		if bytes.HasPrefix(lines[i], []byte("// ")) && len(rest) > 0 && string(rest[0]) == "\n" {
+			rest = rest[1:]
+		}
+		body = append(bytes.Join(lines[:i], nil), bytes.Join(rest, nil)...)
+		return body, string(m[1]), true
+	}
+	return content, "", false
+}
+
+// GeneratedUnmodified reports whether content carries a gsi stamp whose
+// checksum still matches, i.e. gsi produced it and nobody has edited it since.
+func GeneratedUnmodified(content []byte) bool {
+	body, checksum, found := unstampContent(content)
+	return found && contentChecksum(body) == checksum
+}
+
+// mergeWithMarkers combines the existing and generated versions of a file,
+// keeping lines they share and wrapping every difference in git-style
+// conflict markers. It reports whether any conflicts were written.
+func mergeWithMarkers(existing, generated []byte) ([]byte, bool) {
+	existing, _, _ = unstampContent(existing)
+	generated, _, _ = unstampContent(generated)
+
+	a := splitLines(string(existing))
+	b := splitLines(string(generated))
+	m := difflib.NewMatcherWithJunk(a, b, false, nil)
+
+	var out strings.Builder
+	conflicts := false
+	for _, op := range m.GetOpCodes() {
+		if op.Tag == 'e' {
+			writeLines(&out, a[op.I1:op.I2])
+			continue
+		}
+		conflicts = true
+		out.WriteString("<<<<<<< existing\n")
+		writeLines(&out, a[op.I1:op.I2])
+		out.WriteString("=======\n")
+		writeLines(&out, b[op.J1:op.J2])
+		out.WriteString(">>>>>>> gsi\n")
+	}
+	return []byte(out.String()), conflicts
+}
+
+// splitLines splits s into lines that keep their newline. A missing final
+// newline is added so conflict markers always start on their own line.
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	if !strings.HasSuffix(s, "\n") {
+		s += "\n"
+	}
+	return strings.SplitAfter(s, "\n")[:strings.Count(s, "\n")]
+}
+
+func writeLines(out *strings.Builder, lines []string) {
+	for _, l := range lines {
+		out.WriteString(l)
+	}
+}
+
+// mergeThreeWay merges the changes from base to existing and from base to
+// generated, line by line, in the manner of diff3. Regions changed on only
+// one side take that side; regions both sides changed identically take
+// either; anything else is wrapped in conflict markers. It reports whether
+// any conflicts were written.
+func mergeThreeWay(base, existing, generated []byte) ([]byte, bool) {
+	o := splitLines(string(base))
+	a := splitLines(string(existing))
+	b := splitLines(string(generated))
+	mapA := matchLines(o, a)
+	mapB := matchLines(o, b)
+
+	var out strings.Builder
+	conflicts := false
+	i, ia, ib := 0, 0, 0
+	for i < len(o) || ia < len(a) || ib < len(b) {
+		// Lines present, in step, in all three versions are stable
+		if i < len(o) && mapA[i] == ia && mapB[i] == ib {
+			out.WriteString(o[i])
+			i, ia, ib = i+1, ia+1, ib+1
+			continue
+		}
+
+		// Find the next base line both sides still share; everything before
+		// it is a changed region
+		j := i
+		for j < len(o) && (mapA[j] < ia || mapB[j] < ib) {
+			j++
+		}
+		ja, jb := len(a), len(b)
+		if j < len(o) {
+			ja, jb = mapA[j], mapB[j]
+		}
+		oc, ac, bc := o[i:j], a[ia:ja], b[ib:jb]
+		switch {
+		case equalLines(ac, oc):
+			writeLines(&out, bc)
+		case equalLines(bc, oc), equalLines(ac, bc):
+			writeLines(&out, ac)
+		default:
+			conflicts = true
+			out.WriteString("<<<<<<< existing\n")
+			writeLines(&out, ac)
+			out.WriteString("=======\n")
+			writeLines(&out, bc)
+			out.WriteString(">>>>>>> gsi\n")
+		}
+		i, ia, ib = j, ja, jb
+	}
+	return []byte(out.String()), conflicts
+}
+
+// matchLines maps each line of base to the index of the line it matches in
+// other, or -1 when it was changed or removed.
+func matchLines(base, other []string) []int {
+	m := make([]int, len(base))
+	for i := range m {
+		m[i] = -1
+	}
+	for _, block := range difflib.NewMatcherWithJunk(base, other, false, nil).GetMatchingBlocks() {
+		for k := 0; k < block.Size; k++ {
+			m[block.A+k] = block.B + k
+		}
+	}
+	return m
+}
+
+func equalLines(x, y []string) bool {
+	if len(x) != len(y) {
+		return false
+	}
+	for i := range x {
+		if x[i] != y[i] {
+			return false
+		}
+	}
+	return true
+}
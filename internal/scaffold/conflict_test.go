@@ -0,0 +1,115 @@
+package scaffold
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseConflictPolicy(t *testing.T) {
+	if p, err := ParseConflictPolicy(""); err != nil || p != ConflictSkip {
+		t.Errorf("empty policy = %q, %v; want skip", p, err)
+	}
+	for _, want := range ConflictPolicies {
+		if p, err := ParseConflictPolicy(string(want)); err != nil || p != want {
+			t.Errorf("ParseConflictPolicy(%q) = %q, %v", want, p, err)
+		}
+	}
+	if _, err := ParseConflictPolicy("clobber"); err == nil || !strings.Contains(err.Error(), "backup") {
+		t.Errorf("expected error listing valid policies, got %v", err)
+	}
+}
+
+func TestStampContent(t *testing.T) {
+	tests := []struct {
+		path    string
+		content string
+		prefix  string
+	}{
+		{"main.go", "package main\n", "// Scaffolded by gsi."},
+		{"Makefile", "build:\n", "# Scaffolded by gsi."},
+		{".goreleaser.yml", "version: 2\n", "# Scaffolded by gsi."},
+		{"docs/index.md", "# Title\n", "<!-- Scaffolded by gsi."},
+		{"extra.css", "body {}\n", "/* Scaffolded by gsi."},
+		{"scrape.sh", "#!/bin/sh\necho\n", "#!/bin/sh\n# Scaffolded by gsi."},
+		{"index.html", "<!DOCTYPE html>\n<html>\n", "<!DOCTYPE html>\n<!-- Scaffolded by gsi."},
+	}
+	for _, tt := range tests {
+		stamped := stampContent(tt.path, []byte(tt.content))
+		if !strings.HasPrefix(string(stamped), tt.prefix) {
+			t.Errorf("%s: stamped content %q does not start with %q", tt.path, stamped, tt.prefix)
+		}
+		if !GeneratedUnmodified(stamped) {
+			t.Errorf("%s: freshly stamped content should verify", tt.path)
+		}
+		body, _, found := unstampContent(stamped)
+		if !found || string(body) != tt.content {
+			t.Errorf("%s: unstamp = %q, %v; want %q", tt.path, body, found, tt.content)
+		}
+	}
+}
+
+func TestStampContentGoIsNotDocComment(t *testing.T) {
+	stamped := string(stampContent("main.go", []byte("package main\n")))
+	if !strings.Contains(stamped, "\n\npackage main") {
+		t.Errorf("expected a blank line between stamp and package clause, got %q", stamped)
+	}
+}
+
+func TestStampContentUnknownType(t *testing.T) {
+	if got := stampContent("package.json", []byte("{}\n")); string(got) != "{}\n" {
+		t.Errorf("unknown file types should not be stamped, got %q", got)
+	}
+}
+
+func TestGeneratedUnmodifiedDetectsEdits(t *testing.T) {
+	stamped := string(stampContent("main.go", []byte("package main\n\nfunc main() {}\n")))
+	edited := strings.Replace(stamped, "func main() {}", "func main() { run() }", 1)
+	if GeneratedUnmodified([]byte(edited)) {
+		t.Error("edited file should not verify")
+	}
+	if GeneratedUnmodified([]byte("package main\n")) {
+		t.Error("unstamped file should not verify")
+	}
+}
+
+func TestMergeWithMarkers(t *testing.T) {
+	existing := "a\nmine\nc\n"
+	generated := string(stampContent("x.go", []byte("a\ntheirs\nc\n")))
+
+	merged, conflicts := mergeWithMarkers([]byte(existing), []byte(generated))
+	if !conflicts {
+		t.Fatal("expected conflicts")
+	}
+	want := "a\n<<<<<<< existing\nmine\n=======\ntheirs\n>>>>>>> gsi\nc\n"
+	if string(merged) != want {
+		t.Errorf("merged =\n%s\nwant\n%s", merged, want)
+	}
+
+	if _, conflicts := mergeWithMarkers([]byte("a\n"), stampContent("x.go", []byte("a\n"))); conflicts {
+		t.Error("identical bodies should merge cleanly")
+	}
+}
+
+func TestMergeThreeWay(t *testing.T) {
+	base := "a\nb\nc\nd\ne\n"
+	tests := []struct {
+		name      string
+		existing  string
+		generated string
+		want      string
+		conflicts bool
+	}{
+		{"only local change", "a\nB\nc\nd\ne\n", base, "a\nB\nc\nd\ne\n", false},
+		{"only template change", base, "a\nb\nc\nD\ne\n", "a\nb\nc\nD\ne\n", false},
+		{"both, separate lines", "a\nB\nc\nd\ne\n", "a\nb\nc\nD\ne\n", "a\nB\nc\nD\ne\n", false},
+		{"same change on both sides", "a\nX\nc\nd\ne\n", "a\nX\nc\nd\ne\n", "a\nX\nc\nd\ne\n", false},
+		{"local addition, template removal", "a\nb\nnew\nc\nd\ne\n", "a\nb\nc\nd\n", "a\nb\nnew\nc\nd\n", false},
+		{"conflict", "a\nmine\nc\nd\ne\n", "a\ntheirs\nc\nd\ne\n", "a\n<<<<<<< existing\nmine\n=======\ntheirs\n>>>>>>> gsi\nc\nd\ne\n", true},
+	}
+	for _, tt := range tests {
+		got, conflicts := mergeThreeWay([]byte(base), []byte(tt.existing), []byte(tt.generated))
+		if string(got) != tt.want || conflicts != tt.conflicts {
+			t.Errorf("%s: got %q (conflicts=%v), want %q (conflicts=%v)", tt.name, got, conflicts, tt.want, tt.conflicts)
+		}
+	}
+}
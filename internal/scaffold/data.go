@@ -0,0 +1,154 @@
+package scaffold
+
+import (
+	"fmt"
+	"maps"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/joescharf/gsi/internal/templates"
+)
+
+// DefaultLicense is the license used when none is configured.
+const DefaultLicense = "MIT"
+
+// Licenses lists the SPDX identifiers the LICENSE template supports, plus
+// "none" for no LICENSE file.
+var Licenses = []string{"MIT", "Apache-2.0", "BSD-3-Clause", "ISC", "none"}
+
+// ParseLicense validates a --license value, returning its canonical SPDX
+// spelling. Matching is case-insensitive and empty means DefaultLicense.
+func ParseLicense(s string) (string, error) {
+	if s == "" {
+		return DefaultLicense, nil
+	}
+	for _, l := range Licenses {
+		if strings.EqualFold(l, s) {
+			return l, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported license %q (supported: %s)", s, strings.Join(Licenses, ", "))
+}
+
+var angleEmail = regexp.MustCompile(`^(.*?)\s*<([^<>\s]+@[^<>\s]+)>$`)
+
+// ParseAuthor splits an author string into name and email. It accepts
+// "Jane Doe <jane@example.com>", "Jane Doe jane@example.com", a bare email,
+// or a bare name.
+func ParseAuthor(author string) (name, email string) {
+	author = strings.TrimSpace(author)
+	if m := angleEmail.FindStringSubmatch(author); m != nil {
+		return m[1], m[2]
+	}
+	fields := strings.Fields(author)
+	if n := len(fields); n > 0 && strings.Contains(fields[n-1], "@") {
+		return strings.Join(fields[:n-1], " "), fields[n-1]
+	}
+	return author, ""
+}
+
+var goVersionPattern = regexp.MustCompile(`^go(\d+\.\d+)`)
+
+// DetectGoVersion returns the major.minor version of the go toolchain on
+// PATH (e.g. "1.24"), falling back to the version gsi was built with.
+func DetectGoVersion() string {
+	if out, err := exec.Command("go", "env", "GOVERSION").Output(); err == nil {
+		if v := goMinorVersion(strings.TrimSpace(string(out))); v != "" {
+			return v
+		}
+	}
+	return goMinorVersion(runtime.Version())
+}
+
+func goMinorVersion(v string) string {
+	if m := goVersionPattern.FindStringSubmatch(v); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// resolveProjectDefaults fills in the project settings a run did not
+// configure, so they are fixed for the whole run and can be recorded in the
+// manifest.
+func (s *Scaffolder) resolveProjectDefaults() error {
+	cfg := &s.Config
+	license, err := ParseLicense(cfg.License)
+	if err != nil {
+		return err
+	}
+	cfg.License = license
+	if cfg.Year == 0 {
+		cfg.Year = time.Now().Year()
+	}
+	if cfg.GoVersion == "" {
+		cfg.GoVersion = DetectGoVersion()
+	}
+	ci, err := ParseCI(cfg.CI)
+	if err != nil {
+		return err
+	}
+	cfg.CI = ci
+	if cfg.CI == "" {
+		cfg.CI = s.ciProvider()
+	}
+	return nil
+}
+
+// templateData returns the variables templates render with. Settings left
+// unset (in tests, or before resolveProjectDefaults) fall back to defaults.
+func (s *Scaffolder) templateData() templates.Data {
+	cfg := s.Config
+	// A bad --repo-url is rejected by validateIdentifiers before rendering.
+	repo, _ := ParseRepository(cfg.GoModulePath, cfg.RepoURL)
+	id := DeriveIdentifiers(cfg.ProjectName, repo)
+
+	name, email := ParseAuthor(cfg.Author)
+	description := cfg.Description
+	if description == "" {
+		description = cfg.ProjectName + " CLI application"
+	}
+	license := cfg.License
+	if license == "" {
+		license = DefaultLicense
+	}
+	year := cfg.Year
+	if year == 0 {
+		year = time.Now().Year()
+	}
+	goVersion := cfg.GoVersion
+	if goVersion == "" {
+		goVersion = goMinorVersion(runtime.Version())
+	}
+
+	return templates.Data{
+		ProjectName:       cfg.ProjectName,
+		ProjectNameUpper:  strings.ToUpper(cfg.ProjectName),
+		BinaryName:        id.BinaryName,
+		EnvPrefix:         id.EnvPrefix,
+		GoPackageName:     id.GoPackageName,
+		PythonPackageName: id.PythonPackageName,
+		DockerImage:       id.DockerImage,
+		DockerUser:        id.DockerUser,
+		DockerRegistry:    repo.Registry(),
+		GoModulePath:      cfg.GoModulePath,
+		GoModuleOwner:     repo.Owner(),
+		Forge:             repo.Forge,
+		RepoHost:          repo.Host,
+		RepoOwner:         repo.Namespace,
+		RepoName:          repo.Name,
+		RepoURL:           repo.URL,
+		PagesURL:          repo.PagesURL(),
+		CI:                s.ciProvider(),
+		Description:       description,
+		Author:            cfg.Author,
+		AuthorName:        name,
+		AuthorEmail:       email,
+		Year:              year,
+		License:           license,
+		GoVersion:         goVersion,
+		Capabilities:      maps.Clone(cfg.Capabilities),
+	}
+}
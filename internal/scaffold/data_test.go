@@ -0,0 +1,112 @@
+package scaffold
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseAuthor(t *testing.T) {
+	tests := []struct {
+		in, name, email string
+	}{
+		{"Jane Doe jane@example.com", "Jane Doe", "jane@example.com"},
+		{"Jane Doe <jane@example.com>", "Jane Doe", "jane@example.com"},
+		{"<jane@example.com>", "", "jane@example.com"},
+		{"jane@example.com", "", "jane@example.com"},
+		{"Jane Doe", "Jane Doe", ""},
+		{"", "", ""},
+	}
+	for _, tt := range tests {
+		name, email := ParseAuthor(tt.in)
+		if name != tt.name || email != tt.email {
+			t.Errorf("ParseAuthor(%q) = %q, %q; want %q, %q", tt.in, name, email, tt.name, tt.email)
+		}
+	}
+}
+
+func TestParseLicense(t *testing.T) {
+	for in, want := range map[string]string{"": "MIT", "mit": "MIT", "apache-2.0": "Apache-2.0", "NONE": "none"} {
+		got, err := ParseLicense(in)
+		if err != nil || got != want {
+			t.Errorf("ParseLicense(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseLicense("GPL-1.0"); err == nil || !strings.Contains(err.Error(), "supported") {
+		t.Errorf("expected unsupported license error, got %v", err)
+	}
+}
+
+func TestGoMinorVersion(t *testing.T) {
+	for in, want := range map[string]string{"go1.24.1": "1.24", "go1.25rc1": "1.25", "devel": ""} {
+		if got := goMinorVersion(in); got != want {
+			t.Errorf("goMinorVersion(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestTemplateData(t *testing.T) {
+	s, _, _ := testScaffolder(t, false)
+	s.Config.License = "Apache-2.0"
+	s.Config.Year = 2024
+	s.Config.GoVersion = "1.23"
+
+	d := s.templateData()
+	if d.AuthorName != "Test Author" || d.AuthorEmail != "test@example.com" {
+		t.Errorf("author = %q, %q", d.AuthorName, d.AuthorEmail)
+	}
+	if d.Description != "testproj CLI application" {
+		t.Errorf("default description = %q", d.Description)
+	}
+	if d.License != "Apache-2.0" || d.Year != 2024 || d.GoVersion != "1.23" {
+		t.Errorf("data = %+v", d)
+	}
+	if !d.Capabilities[CapDocker] {
+		t.Error("expected capabilities in template data")
+	}
+	d.Capabilities[CapDocker] = false
+	if !s.Config.IsEnabled(CapDocker) {
+		t.Error("template data should not share the config's capability map")
+	}
+}
+
+func TestResolveProjectDefaults(t *testing.T) {
+	s, _, _ := testScaffolder(t, false)
+	if err := s.resolveProjectDefaults(); err != nil {
+		t.Fatal(err)
+	}
+	if s.Config.License != DefaultLicense || s.Config.Year != time.Now().Year() || s.Config.GoVersion == "" {
+		t.Errorf("resolved config = %+v", s.Config)
+	}
+
+	s.Config.License = "WTFPL"
+	if err := s.resolveProjectDefaults(); err == nil {
+		t.Error("expected error for unsupported license")
+	}
+}
+
+func TestLicenseStep(t *testing.T) {
+	s, _, _ := testScaffolder(t, false)
+	s.Config.Year = 2025
+	if err := runStep(t, s, "license"); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(filepath.Join(s.Config.ProjectDir, "LICENSE"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(got), "MIT License\n\nCopyright (c) 2025 Test Author\n") {
+		t.Errorf("unexpected LICENSE:\n%s", got)
+	}
+
+	s, _, _ = testScaffolder(t, false)
+	s.Config.License = "none"
+	if err := runStep(t, s, "license"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(s.Config.ProjectDir, "LICENSE")); err == nil {
+		t.Error("--license none should not write a LICENSE")
+	}
+}
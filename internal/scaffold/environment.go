@@ -16,7 +16,8 @@ func CheckCommand(cmd string) bool {
 }
 
 // ValidateEnvironment checks that required tools are available, matching the shell
-// script's validate_environment() logic.
+// script's validate_environment() logic. With --offline it also checks that
+// every step needing the network has its artifact cached.
 func ValidateEnvironment(cfg *Config, log *logger.Logger) error {
 	if cfg.OnlyDocs {
 		log.Info("Validating environment (docs-only mode)...")
@@ -25,6 +26,11 @@ func ValidateEnvironment(cfg *Config, log *logger.Logger) error {
 			log.Error("Install uv: https://docs.astral.sh/uv/")
 			return fmt.Errorf("uv is required for docs scaffolding")
 		}
+		if cfg.Offline {
+			if err := validateOffline(cfg, log); err != nil {
+				return err
+			}
+		}
 		log.Success("Environment validation complete")
 		return nil
 	}
@@ -57,7 +63,8 @@ func ValidateEnvironment(cfg *Config, log *logger.Logger) error {
 		}
 	}
 
-	if cfg.IsEnabled(CapBmad) {
+	// Offline, BMAD is copied from the artifact cache and needs no npx
+	if cfg.IsEnabled(CapBmad) && !cfg.Offline {
 		if !CheckCommand("npx") {
 			log.Warning("npx is not installed — auto-disabling bmad capability")
 			cfg.Disable(CapBmad)
@@ -84,6 +91,13 @@ func ValidateEnvironment(cfg *Config, log *logger.Logger) error {
 		}
 	}
 
+	// Fail before any step runs when a step would need the network
+	if cfg.Offline {
+		if err := validateOffline(cfg, log); err != nil {
+			return err
+		}
+	}
+
 	log.Success("Environment validation complete")
 	return nil
 }
@@ -1,37 +1,97 @@
 package scaffold
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
 
 	"github.com/joescharf/gsi/internal/logger"
 )
 
-// Executor runs shell commands with dry-run support.
+// DefaultTimeout bounds a Command without its own Timeout. It is generous
+// since installers such as npx and bun download on first use.
+const DefaultTimeout = 10 * time.Minute
+
+// Command is an external command. Args are passed to the program as they
+// are, never through a shell, so project names and authors need no quoting.
+type Command struct {
+	Args    []string      // program and arguments
+	Env     []string      // KEY=value pairs added to gsi's environment
+	Dir     string        // working directory; empty means the Executor's Dir
+	Timeout time.Duration // zero means DefaultTimeout
+	Stdout  io.Writer     // receives stdout instead of the run log, e.g. for jq
+}
+
+// Cmd returns a Command running name with args.
+func Cmd(name string, args ...string) Command {
+	return Command{Args: append([]string{name}, args...)}
+}
+
+// String formats c as a shell command line, for logs and plans.
+func (c Command) String() string {
+	words := make([]string, 0, len(c.Env)+len(c.Args))
+	for _, kv := range c.Env {
+		if k, v, ok := strings.Cut(kv, "="); ok {
+			words = append(words, k+"="+shellWord(v))
+		}
+	}
+	for _, arg := range c.Args {
+		words = append(words, shellWord(arg))
+	}
+	return strings.Join(words, " ")
+}
+
+// Executor runs commands with dry-run support. Output is captured rather
+// than streamed: it goes to Log, is echoed with Logger.Verbose, and is
+// printed when a command fails.
 type Executor struct {
-	DryRun bool
-	Logger *logger.Logger
-	Dir    string // working directory for commands
+	DryRun   bool
+	Logger   *logger.Logger
+	Dir      string          // working directory for commands
+	Recorder *Recorder       // optional; records every Execute call
+	Log      io.Writer       // optional; receives every command and its output
+	Context  context.Context // cancels running commands, e.g. on SIGINT; nil means never
 }
 
-// Execute runs a shell command via sh -c. It mirrors the shell script's execute() function.
-func (e *Executor) Execute(command, description string) error {
+// Execute runs c. It mirrors the shell script's execute() function.
+func (e *Executor) Execute(c Command, description string) error {
+	dir := e.dir(c)
 	e.Logger.Info(description)
-	e.Logger.VerboseMsg("Command: " + command)
+	e.Logger.VerboseMsg("Command: " + c.String())
+	e.Recorder.Command(CommandAction{Command: c.String(), Dir: dir, Description: description})
 
 	if e.DryRun {
-		e.Logger.Warning(fmt.Sprintf("[DRY-RUN] Would execute: %s", command))
+		e.Logger.Warning(fmt.Sprintf("[DRY-RUN] Would execute: %s", c))
 		return nil
 	}
 
-	cmd := exec.Command("sh", "-c", command)
-	cmd.Dir = e.Dir
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	var out bytes.Buffer
+	output := io.Writer(&out)
+	if e.Log != nil {
+		fmt.Fprintf(e.Log, "$ %s  # in %s\n", c, dir)
+		output = io.MultiWriter(output, e.Log)
+	}
+	if e.Logger.Verbose {
+		output = io.MultiWriter(output, e.Logger.Stdout)
+	}
+	stdout := output
+	if c.Stdout != nil {
+		stdout = c.Stdout
+	}
 
-	if err := cmd.Run(); err != nil {
+	if err := e.run(c, dir, stdout, output); err != nil {
 		e.Logger.Error(description + " - Failed")
+		if !e.Logger.Verbose && out.Len() > 0 {
+			fmt.Fprint(e.Logger.Stderr, out.String())
+		}
 		return fmt.Errorf("%s: %w", description, err)
 	}
 
@@ -39,18 +99,72 @@ func (e *Executor) Execute(command, description string) error {
 	return nil
 }
 
-// RunCommand runs a command directly (not via shell).
-func (e *Executor) RunCommand(name string, args ...string) error {
-	cmd := exec.Command(name, args...)
-	cmd.Dir = e.Dir
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	return cmd.Run()
-}
-
 // RunCommandQuiet runs a command suppressing all output. Used for existence checks.
 func (e *Executor) RunCommandQuiet(name string, args ...string) error {
-	cmd := exec.Command(name, args...)
-	cmd.Dir = e.Dir
-	return cmd.Run()
+	c := Cmd(name, args...)
+	return e.run(c, e.dir(c), io.Discard, io.Discard)
+}
+
+func (e *Executor) dir(c Command) string {
+	if c.Dir == "" {
+		return e.Dir
+	}
+	if filepath.IsAbs(c.Dir) || e.Dir == "" {
+		return c.Dir
+	}
+	return filepath.Join(e.Dir, c.Dir)
+}
+
+// run starts c in its own process group and waits for it, killing the group
+// when the timeout passes or the Executor's context is cancelled.
+func (e *Executor) run(c Command, dir string, stdout, stderr io.Writer) error {
+	if len(c.Args) == 0 {
+		return fmt.Errorf("empty command")
+	}
+	parent := e.Context
+	if parent == nil {
+		parent = context.Background()
+	}
+	timeout := c.Timeout
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(parent, timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, c.Args[0], c.Args[1:]...)
+	cmd.Dir = dir
+	if len(c.Env) > 0 {
+		cmd.Env = append(os.Environ(), c.Env...)
+	}
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+	killProcessGroup(cmd)
+	// Don't wait forever on output held open by an orphaned grandchild
+	cmd.WaitDelay = 5 * time.Second
+
+	err := cmd.Run()
+	switch {
+	case err == nil:
+		return nil
+	case parent.Err() != nil:
+		return fmt.Errorf("interrupted")
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return fmt.Errorf("timed out after %s", timeout)
+	}
+	return err
+}
+
+var plainWord = regexp.MustCompile(`^[A-Za-z0-9_@%+=:,./-]+$`)
+
+// shellWord quotes s for a shell command line unless it needs no quoting.
+func shellWord(s string) string {
+	if plainWord.MatchString(s) {
+		return s
+	}
+	return shellQuote(s)
+}
+
+// shellQuote quotes s for use as a single word in a shell command line.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
 }
@@ -2,8 +2,10 @@ package scaffold
 
 import (
 	"bytes"
+	"context"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/joescharf/gsi/internal/logger"
 )
@@ -18,11 +20,11 @@ func TestExecuteDryRun(t *testing.T) {
 	log, _, stderr := testLogger()
 	exec := &Executor{DryRun: true, Logger: log, Dir: t.TempDir()}
 
-	err := exec.Execute("echo hello", "Print hello")
+	err := exec.Execute(Cmd("echo", "hello"), "Print hello")
 	if err != nil {
 		t.Fatalf("dry-run execute should not error: %v", err)
 	}
-	if !strings.Contains(stderr.String(), "[DRY-RUN]") {
+	if !strings.Contains(stderr.String(), "[DRY-RUN] Would execute: echo hello") {
 		t.Errorf("expected dry-run message in stderr, got %q", stderr.String())
 	}
 }
@@ -31,7 +33,7 @@ func TestExecuteRealCommand(t *testing.T) {
 	log, _, _ := testLogger()
 	exec := &Executor{DryRun: false, Logger: log, Dir: t.TempDir()}
 
-	err := exec.Execute("true", "Run true")
+	err := exec.Execute(Cmd("true"), "Run true")
 	if err != nil {
 		t.Fatalf("expected success, got: %v", err)
 	}
@@ -41,8 +43,96 @@ func TestExecuteFailingCommand(t *testing.T) {
 	log, _, _ := testLogger()
 	exec := &Executor{DryRun: false, Logger: log, Dir: t.TempDir()}
 
-	err := exec.Execute("false", "Run false")
+	err := exec.Execute(Cmd("false"), "Run false")
 	if err == nil {
 		t.Fatal("expected error for failing command")
 	}
 }
+
+func TestExecutePassesArgsVerbatim(t *testing.T) {
+	log, _, _ := testLogger()
+	exec := &Executor{Logger: log, Dir: t.TempDir()}
+
+	arg := `Jane "JD" O'Neil $HOME; rm -rf /`
+	var out bytes.Buffer
+	c := Cmd("printf", "%s|%s", arg, "$FOO")
+	c.Env, c.Stdout = []string{"FOO=bar"}, &out
+	if err := exec.Execute(c, "Print"); err != nil {
+		t.Fatal(err)
+	}
+	if got := out.String(); got != arg+"|$FOO" {
+		t.Errorf("output = %q", got)
+	}
+}
+
+func TestExecuteCapturesOutput(t *testing.T) {
+	log, stdout, stderr := testLogger()
+	log.Verbose = false
+	var runLog bytes.Buffer
+	exec := &Executor{Logger: log, Dir: t.TempDir(), Log: &runLog}
+
+	if err := exec.Execute(Cmd("sh", "-c", "echo fine; echo noisy >&2"), "Quiet"); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(stdout.String()+stderr.String(), "noisy") {
+		t.Errorf("output shown for a successful command: %q %q", stdout, stderr)
+	}
+	if err := exec.Execute(Cmd("sh", "-c", "echo broken >&2; exit 3"), "Loud"); err == nil {
+		t.Fatal("expected an error")
+	}
+	if !strings.Contains(stderr.String(), "broken") {
+		t.Errorf("expected failure output on stderr, got %q", stderr.String())
+	}
+	for _, want := range []string{"$ sh -c 'echo fine; echo noisy >&2'", "fine\n", "noisy\n", "broken\n"} {
+		if !strings.Contains(runLog.String(), want) {
+			t.Errorf("expected %q in run log:\n%s", want, runLog.String())
+		}
+	}
+
+	// --verbose streams output as it comes
+	log.Verbose = true
+	stdout.Reset()
+	if err := exec.Execute(Cmd("echo", "shown"), "Verbose"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(stdout.String(), "shown") {
+		t.Errorf("expected verbose output, got %q", stdout.String())
+	}
+}
+
+func TestExecuteTimeout(t *testing.T) {
+	log, _, _ := testLogger()
+	exec := &Executor{Logger: log, Dir: t.TempDir()}
+
+	c := Cmd("sleep", "10")
+	c.Timeout = 100 * time.Millisecond
+	start := time.Now()
+	err := exec.Execute(c, "Sleep")
+	if err == nil || !strings.Contains(err.Error(), "timed out after 100ms") {
+		t.Errorf("expected a timeout, got %v", err)
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Errorf("timeout took %s", time.Since(start))
+	}
+}
+
+func TestExecuteCancel(t *testing.T) {
+	log, _, _ := testLogger()
+	ctx, cancel := context.WithCancel(context.Background())
+	exec := &Executor{Logger: log, Dir: t.TempDir(), Context: ctx}
+
+	time.AfterFunc(100*time.Millisecond, cancel)
+	err := exec.Execute(Cmd("sleep", "10"), "Sleep")
+	if err == nil || !strings.Contains(err.Error(), "interrupted") {
+		t.Errorf("expected an interruption, got %v", err)
+	}
+}
+
+func TestCommandString(t *testing.T) {
+	c := Cmd("git", "commit", "-m", "initial commit")
+	c.Env = []string{"GOPROXY=file:///a b", "GOSUMDB=off"}
+	want := `GOPROXY='file:///a b' GOSUMDB=off git commit -m 'initial commit'`
+	if got := c.String(); got != want {
+		t.Errorf("String() = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,19 @@
+//go:build !windows
+
+package scaffold
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// killProcessGroup starts cmd in a process group of its own and makes
+// cancelling it kill the whole group, so installers that spawn children
+// (npx, bun, uv) stop with it. It also keeps a terminal's Ctrl-C from
+// reaching the child directly; gsi cancels it instead.
+func killProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
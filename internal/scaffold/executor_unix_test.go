@@ -0,0 +1,57 @@
+//go:build !windows
+
+package scaffold
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestExecuteCancelKillsProcessGroup(t *testing.T) {
+	log, _, _ := testLogger()
+	dir := t.TempDir()
+	ctx, cancel := context.WithCancel(context.Background())
+	exec := &Executor{Logger: log, Dir: dir, Context: ctx}
+
+	// The shell starts a grandchild and waits for it
+	pidFile := filepath.Join(dir, "pid")
+	go func() {
+		for range 50 {
+			// The shell creates the file before writing the pid to it
+			if data, _ := os.ReadFile(pidFile); strings.HasSuffix(string(data), "\n") {
+				break
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+		cancel()
+	}()
+	if err := exec.Execute(Cmd("sh", "-c", "sleep 30 & echo $! > pid; wait"), "Wait"); err == nil {
+		t.Fatal("expected an interruption")
+	}
+
+	data, err := os.ReadFile(pidFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The killed grandchild may linger as a zombie briefly; poll until it's reaped or gone
+	for range 50 {
+		if err := syscall.Kill(pid, 0); err != nil {
+			return
+		}
+		if status, _ := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "stat")); strings.Contains(string(status), ") Z") {
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Errorf("grandchild %d still running after cancel", pid)
+}
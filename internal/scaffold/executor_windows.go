@@ -0,0 +1,9 @@
+//go:build windows
+
+package scaffold
+
+import "os/exec"
+
+// killProcessGroup leaves cmd as it is: cancelling kills the process itself,
+// which is exec.CommandContext's default.
+func killProcessGroup(cmd *exec.Cmd) {}
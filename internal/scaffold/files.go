@@ -1,106 +1,381 @@
 package scaffold
 
 import (
+	"bytes"
 	"fmt"
+	"io"
+	"maps"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 
 	"github.com/joescharf/gsi/internal/logger"
 	"github.com/joescharf/gsi/internal/templates"
+	"github.com/pmezard/go-difflib/difflib"
 )
 
-// WriteTemplateFile renders a template and writes it to path, skipping if the file already exists.
-func WriteTemplateFile(path, templateName string, data templates.Data, dryRun bool, log *logger.Logger) error {
-	return writeTemplateFileWithMode(path, templateName, data, 0o644, dryRun, log)
+// FileWriter renders templates and static content into an FS. In dry-run
+// mode the FS is expected to be a MemFS so content is still rendered (and
+// diffable) without touching disk.
+type FileWriter struct {
+	FS     FS
+	DryRun bool
+	Diff   bool   // log a unified diff when an existing file would change
+	Root   string // diff labels and recorded paths are relative to Root when set
+	Logger *logger.Logger
+
+	// Policy decides what happens to an existing file with different
+	// content. Files gsi generated and nobody has edited since are always
+	// updated; the policy only applies to hand-edited files.
+	Policy ConflictPolicy
+	// Fresh holds files created earlier in this run by an external tool
+	// (bun init), which are replaced without a conflict.
+	Fresh map[string]bool
+	// Manifest is the project's manifest from an earlier run, if any. Files
+	// whose content still matches their entry count as unmodified.
+	Manifest *Manifest
+	// BaselineDir, when set, receives a copy of every render that is
+	// written, mirroring the project layout (see BaselineDir).
+	BaselineDir string
+	// In supplies answers for the prompt policy (default os.Stdin).
+	In       io.Reader
+	Recorder *Recorder
+	// Renderer reads the templates; nil means the built-in ones.
+	Renderer *templates.Renderer
+}
+
+// WriteTemplate renders a template, stamps it with the gsi header and writes
+// it to path with the given file mode. An existing file is resolved with the
+// writer's Policy.
+//
+// A template that renders to nothing but whitespace writes no file, so a
+// template can opt out for some settings (license.tmpl with --license none).
+func (w *FileWriter) WriteTemplate(path, templateName string, data templates.Data, mode os.FileMode) error {
+	content, err := w.render(path, templateName, data)
+	if err != nil {
+		return err
+	}
+	if content == nil {
+		w.Logger.VerboseMsg(fmt.Sprintf("Skipping %s (%s rendered nothing)", path, templateName))
+		return nil
+	}
+	return w.put(path, templateName, content, mode, w.Policy)
+}
+
+// OverwriteTemplate renders a template and writes it to path, replacing any
+// existing file regardless of Policy.
+func (w *FileWriter) OverwriteTemplate(path, templateName string, data templates.Data) error {
+	ow := *w
+	ow.Policy = ConflictOverwrite
+	return ow.WriteTemplate(path, templateName, data, 0o644)
 }
 
-// WriteExecutableTemplateFile renders a template and writes it to path with executable permissions (0o755),
-// skipping if the file already exists.
-func WriteExecutableTemplateFile(path, templateName string, data templates.Data, dryRun bool, log *logger.Logger) error {
-	return writeTemplateFileWithMode(path, templateName, data, 0o755, dryRun, log)
+// WriteStatic writes static content to path unstamped. An existing file is
+// resolved with the writer's Policy.
+func (w *FileWriter) WriteStatic(path string, content []byte) error {
+	return w.put(path, "", content, 0o644, w.Policy)
 }
 
-// writeTemplateFileWithMode renders a template and writes it to path with the given file mode,
-// skipping if the file already exists.
-func writeTemplateFileWithMode(path, templateName string, data templates.Data, mode os.FileMode, dryRun bool, log *logger.Logger) error {
-	if _, err := os.Stat(path); err == nil {
-		log.Info(path + " already exists, skipping")
+func (w *FileWriter) render(path, templateName string, data templates.Data) ([]byte, error) {
+	content, err := w.Renderer.Render(templateName, data)
+	if err != nil {
+		return nil, fmt.Errorf("rendering template %s: %w", templateName, err)
+	}
+	if strings.TrimSpace(content) == "" {
+		return nil, nil
+	}
+	return stampContent(path, []byte(content)), nil
+}
+
+// put writes content to path, applying policy when a different, hand-edited
+// file is already there. The action taken is recorded with the hash of
+// content, whether or not content ends up on disk.
+func (w *FileWriter) put(path, templateName string, content []byte, mode os.FileMode, policy ConflictPolicy) error {
+	record := func(action string) {
+		w.Recorder.File(FileAction{
+			Path:     relTo(w.Root, path),
+			Action:   action,
+			Template: templateName,
+			Mode:     fileModeString(mode),
+			Hash:     ContentHash(content),
+		})
+	}
+	commit := func(action string, data []byte) error {
+		record(action)
+		if err := w.write(path, data, mode, action); err != nil {
+			return err
+		}
+		return w.saveBaseline(path, templateName, content)
+	}
+
+	existing, err := w.FS.ReadFile(path)
+	if err != nil {
+		return commit(ActionCreate, content)
+	}
+	if bytes.Equal(existing, content) {
+		w.Logger.Info(path + " is unchanged")
+		record(ActionSkip)
 		return nil
 	}
+	if w.Fresh[path] {
+		return commit(ActionOverwrite, content)
+	}
+	if policy == conflictKeep {
+		w.Logger.Info(path + " already exists, skipping")
+		record(ActionSkip)
+		return nil
+	}
+	if w.unmodified(path, existing) {
+		return commit(ActionOverwrite, content)
+	}
 
-	if dryRun {
-		log.Warning(fmt.Sprintf("[DRY-RUN] Would create %s", path))
+	if policy == ConflictPrompt {
+		policy = w.ask(path, existing, content)
+	}
+	switch policy {
+	case ConflictOverwrite:
+		return commit(ActionOverwrite, content)
+	case ConflictBackup:
+		if err := w.backup(path, existing); err != nil {
+			return err
+		}
+		return commit(ActionBackup, content)
+	case ConflictMerge:
+		merged, conflicts := mergeWithMarkers(existing, content)
+		if !conflicts {
+			return commit(ActionOverwrite, content)
+		}
+		if err := commit(ActionMerge, merged); err != nil {
+			return err
+		}
+		w.Logger.Warning(path + " has conflict markers to resolve")
+		return nil
+	case ConflictPrompt:
+		// Dry run: nothing was asked
+		record(ActionPrompt)
+		return nil
+	default:
+		w.Logger.Info(path + " already exists, skipping")
+		record(ActionSkip)
 		return nil
 	}
+}
 
-	log.Info("Creating " + path)
+// saveBaseline stores content, the unmodified render of templateName, under
+// BaselineDir so a later upgrade can three-way merge against it.
+func (w *FileWriter) saveBaseline(path, templateName string, content []byte) error {
+	if w.BaselineDir == "" || templateName == "" {
+		return nil
+	}
+	dest := filepath.Join(w.BaselineDir, relTo(w.Root, path))
+	if err := w.FS.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return fmt.Errorf("creating baseline directory for %s: %w", path, err)
+	}
+	if err := w.FS.WriteFile(dest, content, 0o644); err != nil {
+		return fmt.Errorf("saving baseline for %s: %w", path, err)
+	}
+	return nil
+}
 
-	content, err := templates.Render(templateName, data)
-	if err != nil {
-		return fmt.Errorf("rendering template %s: %w", templateName, err)
+// unmodified reports whether existing, the current content of path, is
+// exactly what gsi generated for it last time.
+func (w *FileWriter) unmodified(path string, existing []byte) bool {
+	if GeneratedUnmodified(existing) {
+		return true
 	}
+	f, ok := w.Manifest.File(relTo(w.Root, path))
+	return ok && f.Hash == ContentHash(existing)
+}
 
-	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
-		return fmt.Errorf("creating directory for %s: %w", path, err)
+// ask shows the pending change to path and reads the policy to apply from In.
+// Dry runs never ask and return ConflictPrompt unchanged.
+func (w *FileWriter) ask(path string, existing, content []byte) ConflictPolicy {
+	w.Logger.Warning(path + " has been modified since it was generated")
+	w.Logger.Diff(UnifiedDiff(relTo(w.Root, path), string(existing), string(content)))
+	if w.DryRun {
+		w.Logger.Warning(fmt.Sprintf("[DRY-RUN] Would ask how to resolve %s", path))
+		return ConflictPrompt
 	}
 
-	if err := os.WriteFile(path, []byte(content), mode); err != nil {
-		return fmt.Errorf("writing %s: %w", path, err)
+	in := w.In
+	if in == nil {
+		in = os.Stdin
+	}
+	for {
+		fmt.Fprintf(w.Logger.Stdout, "[o]verwrite, [s]kip, [b]ackup, [m]erge %s? [s] ", relTo(w.Root, path))
+		answer, err := readLine(in)
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "o", "overwrite":
+			return ConflictOverwrite
+		case "b", "backup":
+			return ConflictBackup
+		case "m", "merge":
+			return ConflictMerge
+		case "", "s", "skip":
+			return ConflictSkip
+		}
+		if err != nil {
+			return ConflictSkip
+		}
 	}
+}
 
-	log.Success("Created " + path)
-	return nil
+// readLine reads up to a newline one byte at a time so that no input meant
+// for a later prompt is buffered away.
+func readLine(r io.Reader) (string, error) {
+	var line []byte
+	buf := make([]byte, 1)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			if buf[0] == '\n' {
+				return string(line), nil
+			}
+			line = append(line, buf[0])
+		}
+		if err != nil {
+			return string(line), err
+		}
+	}
 }
 
-// OverwriteTemplateFile renders a template and writes it to path, overwriting if the file already exists.
-// This is used for files like main.go and cmd/root.go that cobra-cli creates first.
-func OverwriteTemplateFile(path, templateName string, data templates.Data, dryRun bool, log *logger.Logger) error {
-	if dryRun {
-		log.Warning(fmt.Sprintf("[DRY-RUN] Would create %s", path))
-		return nil
+// backup saves the existing content of path to path+BackupSuffix.
+func (w *FileWriter) backup(path string, existing []byte) error {
+	mode := os.FileMode(0o644)
+	if info, err := w.FS.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if w.DryRun {
+		w.Logger.Warning(fmt.Sprintf("[DRY-RUN] Would back up %s to %s", path, path+BackupSuffix))
+	} else {
+		w.Logger.Info(fmt.Sprintf("Backing up %s to %s", path, path+BackupSuffix))
 	}
+	if err := w.FS.WriteFile(path+BackupSuffix, existing, mode); err != nil {
+		return fmt.Errorf("backing up %s: %w", path, err)
+	}
+	return nil
+}
 
-	log.Info("Creating " + path)
+// writeVerbs describes each action write can take: the dry-run verb, and
+// the messages logged before and after the write.
+var writeVerbs = map[string][3]string{
+	ActionCreate:    {"create", "Creating", "Created"},
+	ActionOverwrite: {"overwrite", "Overwriting", "Overwrote"},
+	ActionBackup:    {"overwrite", "Overwriting", "Overwrote"},
+	ActionMerge:     {"merge", "Merging", "Merged"},
+}
 
-	content, err := templates.Render(templateName, data)
-	if err != nil {
-		return fmt.Errorf("rendering template %s: %w", templateName, err)
+// write stores content at path, logging action (one of the Action
+// constants) and showing a diff against any existing content first when Diff
+// is set.
+func (w *FileWriter) write(path string, content []byte, mode os.FileMode, action string) error {
+	existing, readErr := w.FS.ReadFile(path)
+	exists := readErr == nil
+
+	verbs, ok := writeVerbs[action]
+	if !ok {
+		verbs = writeVerbs[ActionCreate]
+	}
+	if w.DryRun {
+		w.Logger.Warning(fmt.Sprintf("[DRY-RUN] Would %s %s", verbs[0], path))
+	} else {
+		w.Logger.Info(verbs[1] + " " + path)
 	}
 
-	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
-		return fmt.Errorf("creating directory for %s: %w", path, err)
+	if w.Diff && exists {
+		w.Logger.Diff(UnifiedDiff(relTo(w.Root, path), string(existing), string(content)))
 	}
 
-	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+	if err := w.FS.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("creating directory for %s: %w", path, err)
+	}
+	if err := w.FS.WriteFile(path, content, mode); err != nil {
 		return fmt.Errorf("writing %s: %w", path, err)
 	}
 
-	log.Success("Created " + path)
+	if !w.DryRun {
+		w.Logger.Success(verbs[2] + " " + path)
+	}
 	return nil
 }
 
-// WriteStaticFile writes static content to path, skipping if the file already exists.
-func WriteStaticFile(path string, content []byte, dryRun bool, log *logger.Logger) error {
-	if _, err := os.Stat(path); err == nil {
-		log.Info(path + " already exists, skipping")
-		return nil
+// UnifiedDiff returns a unified diff from oldContent to newContent, labelled
+// with path. It returns "" when the contents are identical.
+func UnifiedDiff(path, oldContent, newContent string) string {
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(oldContent),
+		B:        difflib.SplitLines(newContent),
+		FromFile: "a/" + filepath.ToSlash(path),
+		ToFile:   "b/" + filepath.ToSlash(path),
+		Context:  3,
+	})
+	if err != nil {
+		return ""
 	}
+	return diff
+}
 
-	if dryRun {
-		log.Warning(fmt.Sprintf("[DRY-RUN] Would create %s", path))
-		return nil
+// writer returns the Scaffolder's FileWriter over its FS.
+func (s *Scaffolder) writer() *FileWriter {
+	return &FileWriter{
+		FS:          s.FS,
+		DryRun:      s.Config.DryRun,
+		Diff:        s.Config.Diff,
+		Root:        s.Config.ProjectDir,
+		Logger:      s.Logger,
+		Policy:      s.Config.OnConflict,
+		Fresh:       s.fresh.snapshot(),
+		Manifest:    s.manifest,
+		BaselineDir: filepath.Join(s.Config.ProjectDir, BaselineDir),
+		In:          s.In,
+		Recorder:    s.Recorder,
+		Renderer:    s.renderer,
 	}
+}
 
-	log.Info("Creating " + path)
+// writeTemplate renders a template to path, resolving an existing file with
+// the configured conflict policy.
+func (s *Scaffolder) writeTemplate(path, templateName string) error {
+	return s.writer().WriteTemplate(path, templateName, s.templateData(), 0o644)
+}
 
-	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
-		return fmt.Errorf("creating directory for %s: %w", path, err)
+// markFresh records files an external tool just created so later template
+// writes replace them instead of treating them as conflicts. Dry runs never
+// run those tools, so nothing is marked.
+func (s *Scaffolder) markFresh(paths ...string) {
+	if s.Config.DryRun {
+		return
 	}
+	if s.fresh == nil {
+		s.fresh = &pathSet{}
+	}
+	s.fresh.add(paths...)
+}
 
-	if err := os.WriteFile(path, content, 0o644); err != nil {
-		return fmt.Errorf("writing %s: %w", path, err)
+// pathSet is a set of paths that steps running concurrently can share.
+type pathSet struct {
+	mu    sync.Mutex
+	paths map[string]bool
+}
+
+func (p *pathSet) add(paths ...string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.paths == nil {
+		p.paths = map[string]bool{}
+	}
+	for _, path := range paths {
+		p.paths[path] = true
 	}
+}
 
-	log.Success("Created " + path)
-	return nil
+// snapshot returns a copy of the set; nil for a nil or empty set.
+func (p *pathSet) snapshot() map[string]bool {
+	if p == nil {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return maps.Clone(p.paths)
 }
@@ -9,15 +9,12 @@ import (
 	"github.com/joescharf/gsi/internal/templates"
 )
 
-func TestWriteTemplateFileCreatesFile(t *testing.T) {
-	log, _, _ := testLogger()
-	dir := t.TempDir()
-	path := filepath.Join(dir, "test.txt")
+func TestWriterCreatesFile(t *testing.T) {
+	s, stdout, _ := testScaffolder(t, false)
+	path := filepath.Join(s.Config.ProjectDir, ".editorconfig")
 
-	data := templates.Data{ProjectName: "myapp", GoModulePath: "github.com/example/myapp"}
-	err := WriteTemplateFile(path, "editorconfig.tmpl", data, false, log)
-	if err != nil {
-		t.Fatalf("WriteTemplateFile failed: %v", err)
+	if err := s.writer().WriteTemplate(path, "editorconfig.tmpl", s.templateData(), 0o644); err != nil {
+		t.Fatalf("WriteTemplate failed: %v", err)
 	}
 
 	content, err := os.ReadFile(path)
@@ -27,22 +24,22 @@ func TestWriteTemplateFileCreatesFile(t *testing.T) {
 	if !strings.Contains(string(content), "root = true") {
 		t.Errorf("expected editorconfig content, got %q", string(content))
 	}
+	if !strings.Contains(stdout.String(), "Created "+path) {
+		t.Errorf("expected create message, got %q", stdout.String())
+	}
 }
 
-func TestWriteTemplateFileIdempotent(t *testing.T) {
-	log, stdout, _ := testLogger()
-	dir := t.TempDir()
-	path := filepath.Join(dir, "test.txt")
+func TestWriterKeepsExistingFile(t *testing.T) {
+	s, stdout, _ := testScaffolder(t, false)
+	path := filepath.Join(s.Config.ProjectDir, ".editorconfig")
 
 	// Write original content
 	if err := os.WriteFile(path, []byte("original"), 0o644); err != nil {
 		t.Fatal(err)
 	}
 
-	data := templates.Data{ProjectName: "myapp"}
-	err := WriteTemplateFile(path, "editorconfig.tmpl", data, false, log)
-	if err != nil {
-		t.Fatalf("WriteTemplateFile failed: %v", err)
+	if err := s.writer().WriteTemplate(path, "editorconfig.tmpl", s.templateData(), 0o644); err != nil {
+		t.Fatalf("WriteTemplate failed: %v", err)
 	}
 
 	// Should have skipped
@@ -57,18 +54,15 @@ func TestWriteTemplateFileIdempotent(t *testing.T) {
 	}
 }
 
-func TestWriteTemplateFileDryRun(t *testing.T) {
-	log, _, stderr := testLogger()
-	dir := t.TempDir()
-	path := filepath.Join(dir, "sub", "test.txt")
+func TestWriterDryRun(t *testing.T) {
+	s, _, stderr := testScaffolder(t, true)
+	path := filepath.Join(s.Config.ProjectDir, "sub", "test.sh")
 
-	data := templates.Data{ProjectName: "myapp"}
-	err := WriteTemplateFile(path, "editorconfig.tmpl", data, true, log)
-	if err != nil {
-		t.Fatalf("WriteTemplateFile dry-run failed: %v", err)
+	if err := s.writer().WriteTemplate(path, "editorconfig.tmpl", s.templateData(), 0o755); err != nil {
+		t.Fatalf("WriteTemplate dry-run failed: %v", err)
 	}
 
-	if !strings.Contains(stderr.String(), "[DRY-RUN]") {
+	if !strings.Contains(stderr.String(), "[DRY-RUN] Would create "+path) {
 		t.Errorf("expected dry-run message, got %q", stderr.String())
 	}
 
@@ -78,15 +72,12 @@ func TestWriteTemplateFileDryRun(t *testing.T) {
 	}
 }
 
-func TestWriteExecutableTemplateFileCreatesFile(t *testing.T) {
-	log, _, _ := testLogger()
-	dir := t.TempDir()
-	path := filepath.Join(dir, "test.sh")
+func TestWriterExecutableMode(t *testing.T) {
+	s, _, _ := testScaffolder(t, false)
+	path := filepath.Join(s.Config.ProjectDir, "test.sh")
 
-	data := templates.Data{ProjectName: "myapp", GoModulePath: "github.com/example/myapp"}
-	err := WriteExecutableTemplateFile(path, "editorconfig.tmpl", data, false, log)
-	if err != nil {
-		t.Fatalf("WriteExecutableTemplateFile failed: %v", err)
+	if err := s.writer().WriteTemplate(path, "editorconfig.tmpl", s.templateData(), 0o755); err != nil {
+		t.Fatalf("WriteTemplate failed: %v", err)
 	}
 
 	info, err := os.Stat(path)
@@ -98,51 +89,216 @@ func TestWriteExecutableTemplateFileCreatesFile(t *testing.T) {
 	}
 }
 
-func TestWriteExecutableTemplateFileIdempotent(t *testing.T) {
-	log, stdout, _ := testLogger()
+func TestWriterLogsOverwrite(t *testing.T) {
+	s, stdout, _ := testScaffolder(t, false)
+	path := filepath.Join(s.Config.ProjectDir, ".editorconfig")
+	old := stampContent(path, []byte("root = false\n"))
+	if err := os.WriteFile(path, old, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.writer().WriteTemplate(path, "editorconfig.tmpl", s.templateData(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out := stdout.String()
+	if !strings.Contains(out, "Overwrote "+path) {
+		t.Errorf("expected overwrite message, got %q", out)
+	}
+	if strings.Contains(out, "Created") {
+		t.Errorf("an overwrite should not be logged as a create, got %q", out)
+	}
+}
+
+func TestFileWriterDryRunRendersIntoMemory(t *testing.T) {
+	log, _, _ := testLogger()
 	dir := t.TempDir()
-	path := filepath.Join(dir, "test.sh")
+	path := filepath.Join(dir, ".editorconfig")
 
-	// Write original content
-	if err := os.WriteFile(path, []byte("original"), 0o755); err != nil {
+	mem := NewMemFS(OSFS{})
+	w := &FileWriter{FS: mem, DryRun: true, Logger: log}
+	if err := w.WriteTemplate(path, "editorconfig.tmpl", templates.Data{ProjectName: "myapp"}, 0o644); err != nil {
 		t.Fatal(err)
 	}
 
-	data := templates.Data{ProjectName: "myapp"}
-	err := WriteExecutableTemplateFile(path, "editorconfig.tmpl", data, false, log)
+	content, err := mem.ReadFile(path)
 	if err != nil {
-		t.Fatalf("WriteExecutableTemplateFile failed: %v", err)
+		t.Fatalf("expected rendered content in memory: %v", err)
+	}
+	if !strings.Contains(string(content), "root = true") {
+		t.Errorf("unexpected rendered content %q", content)
+	}
+	if _, err := os.Stat(path); err == nil {
+		t.Error("dry-run should not write to disk")
 	}
+}
 
-	// Should have skipped
+func TestFileWriterDiffOnOverwrite(t *testing.T) {
+	log, stdout, stderr := testLogger()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(path, []byte("package main\n\nfunc main() {}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	w := &FileWriter{FS: NewMemFS(OSFS{}), DryRun: true, Diff: true, Root: dir, Logger: log}
+	data := templates.Data{GoModulePath: "github.com/example/myapp"}
+	if err := w.OverwriteTemplate(path, "main_go.tmpl", data); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(stderr.String(), "Would overwrite") {
+		t.Errorf("expected overwrite message, got %q", stderr.String())
+	}
+	out := stdout.String()
+	for _, want := range []string{"--- a/main.go", "+++ b/main.go", "-func main() {}", "cmd.Execute(version, commit, date)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("diff missing %q:\n%s", want, out)
+		}
+	}
+	disk, _ := os.ReadFile(path)
+	if string(disk) != "package main\n\nfunc main() {}\n" {
+		t.Error("dry-run diff should not modify the file on disk")
+	}
+}
+
+func TestUnifiedDiffIdentical(t *testing.T) {
+	if d := UnifiedDiff("a.txt", "same\n", "same\n"); d != "" {
+		t.Errorf("expected empty diff, got %q", d)
+	}
+}
+
+func TestFileWriterSkipsHandEditedFile(t *testing.T) {
+	log, stdout, _ := testLogger()
+	path := filepath.Join(t.TempDir(), "main.go")
+	if err := os.WriteFile(path, []byte("package main // mine\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	w := &FileWriter{FS: OSFS{}, Logger: log}
+	if err := w.WriteTemplate(path, "main_go.tmpl", templates.Data{}, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	content, _ := os.ReadFile(path)
+	if string(content) != "package main // mine\n" {
+		t.Errorf("hand-edited file should be kept, got %q", content)
+	}
 	if !strings.Contains(stdout.String(), "already exists") {
 		t.Errorf("expected skip message, got %q", stdout.String())
 	}
+}
 
-	// Content should be unchanged
+func TestFileWriterUpdatesUnmodifiedGeneratedFile(t *testing.T) {
+	log, _, _ := testLogger()
+	path := filepath.Join(t.TempDir(), "main.go")
+	old := stampContent(path, []byte("package main\n\nfunc main() {}\n"))
+	if err := os.WriteFile(path, old, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	w := &FileWriter{FS: OSFS{}, Logger: log}
+	if err := w.WriteTemplate(path, "main_go.tmpl", templates.Data{GoModulePath: "github.com/example/myapp"}, 0o644); err != nil {
+		t.Fatal(err)
+	}
 	content, _ := os.ReadFile(path)
-	if string(content) != "original" {
-		t.Errorf("file should not have been overwritten, got %q", string(content))
+	if !strings.Contains(string(content), "cmd.Execute(version, commit, date)") {
+		t.Errorf("unmodified generated file should be updated, got %q", content)
 	}
 }
 
-func TestWriteExecutableTemplateFileDryRun(t *testing.T) {
-	log, _, stderr := testLogger()
-	dir := t.TempDir()
-	path := filepath.Join(dir, "sub", "test.sh")
+func TestFileWriterReplacesFreshFile(t *testing.T) {
+	log, _, _ := testLogger()
+	path := filepath.Join(t.TempDir(), "main.go")
+	if err := os.WriteFile(path, []byte("package main // cobra-cli\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
 
-	data := templates.Data{ProjectName: "myapp"}
-	err := WriteExecutableTemplateFile(path, "editorconfig.tmpl", data, true, log)
-	if err != nil {
-		t.Fatalf("WriteExecutableTemplateFile dry-run failed: %v", err)
+	w := &FileWriter{FS: OSFS{}, Logger: log, Fresh: map[string]bool{path: true}}
+	if err := w.WriteTemplate(path, "main_go.tmpl", templates.Data{GoModulePath: "github.com/example/myapp"}, 0o644); err != nil {
+		t.Fatal(err)
 	}
+	content, _ := os.ReadFile(path)
+	if strings.Contains(string(content), "cobra-cli") {
+		t.Errorf("file created this run should be replaced, got %q", content)
+	}
+}
 
-	if !strings.Contains(stderr.String(), "[DRY-RUN]") {
-		t.Errorf("expected dry-run message, got %q", stderr.String())
+func TestFileWriterBackupPolicy(t *testing.T) {
+	log, _, _ := testLogger()
+	path := filepath.Join(t.TempDir(), "Makefile")
+	if err := os.WriteFile(path, []byte("custom:\n"), 0o600); err != nil {
+		t.Fatal(err)
 	}
 
-	// File should not exist
-	if _, err := os.Stat(path); err == nil {
-		t.Error("expected file to not exist in dry-run mode")
+	w := &FileWriter{FS: OSFS{}, Logger: log, Policy: ConflictBackup}
+	if err := w.WriteTemplate(path, "makefile.tmpl", templates.Data{ProjectName: "myapp"}, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	backup, err := os.ReadFile(path + BackupSuffix)
+	if err != nil || string(backup) != "custom:\n" {
+		t.Fatalf("expected backup with original content, got %q, %v", backup, err)
+	}
+	if info, _ := os.Stat(path + BackupSuffix); info.Mode().Perm() != 0o600 {
+		t.Errorf("backup should keep the original mode, got %o", info.Mode().Perm())
+	}
+	content, _ := os.ReadFile(path)
+	if !GeneratedUnmodified(content) {
+		t.Errorf("expected regenerated Makefile, got %q", content)
+	}
+}
+
+func TestFileWriterMergePolicy(t *testing.T) {
+	log, stdout, stderr := testLogger()
+	path := filepath.Join(t.TempDir(), "main.go")
+	if err := os.WriteFile(path, []byte("package main // mine\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	w := &FileWriter{FS: OSFS{}, Logger: log, Policy: ConflictMerge}
+	if err := w.WriteTemplate(path, "main_go.tmpl", templates.Data{}, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	content, _ := os.ReadFile(path)
+	for _, want := range []string{"<<<<<<< existing\npackage main // mine\n", ">>>>>>> gsi\n"} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("merged file missing %q:\n%s", want, content)
+		}
+	}
+	if !strings.Contains(stderr.String(), "conflict markers") {
+		t.Errorf("expected conflict warning, got %q", stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "Merged "+path) {
+		t.Errorf("expected merge message, got %q", stdout.String())
+	}
+}
+
+func TestFileWriterPromptPolicy(t *testing.T) {
+	tests := []struct {
+		answer    string
+		overwrite bool
+	}{
+		{"o\n", true},
+		{"s\n", false},
+		{"\n", false},
+		{"", false},
+		{"?\no\n", true},
+	}
+	for _, tt := range tests {
+		log, stdout, _ := testLogger()
+		path := filepath.Join(t.TempDir(), "main.go")
+		if err := os.WriteFile(path, []byte("package main // mine\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		w := &FileWriter{FS: OSFS{}, Logger: log, Policy: ConflictPrompt, In: strings.NewReader(tt.answer)}
+		if err := w.WriteTemplate(path, "main_go.tmpl", templates.Data{}, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(stdout.String(), "-package main // mine") {
+			t.Errorf("answer %q: expected a diff before the prompt, got %q", tt.answer, stdout.String())
+		}
+		content, _ := os.ReadFile(path)
+		if overwritten := string(content) != "package main // mine\n"; overwritten != tt.overwrite {
+			t.Errorf("answer %q: overwritten = %v, want %v", tt.answer, overwritten, tt.overwrite)
+		}
 	}
 }
@@ -0,0 +1,202 @@
+package scaffold
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// Forge names, as recorded in Repository.Forge and available to templates as
+// .Forge.
+const (
+	ForgeGitHub = "github"
+	ForgeGitLab = "gitlab"
+	ForgeGitea  = "gitea"
+)
+
+// Repository is where a project is hosted, parsed from --repo-url or, without
+// one, from the Go module path.
+type Repository struct {
+	Forge     string // ForgeGitHub, ForgeGitLab, ForgeGitea, or "" if unknown
+	Host      string // e.g. "github.com", "gitlab.example.com"
+	Namespace string // owner or group path: "joescharf", "group/sub"
+	Name      string // repository name: "my-app"
+	URL       string // web URL, e.g. "https://gitlab.com/group/sub/my-app"; "" if unknown
+}
+
+// forgeHosts maps well-known hosts to their forge. Other hosts are matched
+// by name: a host containing "github", "gitlab", "gitea" or "forgejo".
+var forgeHosts = map[string]string{
+	"github.com":   ForgeGitHub,
+	"gitlab.com":   ForgeGitLab,
+	"codeberg.org": ForgeGitea,
+	"gitea.com":    ForgeGitea,
+}
+
+func forgeForHost(host string) string {
+	host = strings.ToLower(host)
+	if f, ok := forgeHosts[host]; ok {
+		return f
+	}
+	for _, f := range []string{ForgeGitHub, ForgeGitLab, ForgeGitea} {
+		if strings.Contains(host, f) {
+			return f
+		}
+	}
+	if strings.Contains(host, "forgejo") {
+		return ForgeGitea
+	}
+	return ""
+}
+
+// ParseRepository returns the repository of a project. A non-empty repoURL
+// (https://, ssh:// or git@host:path) is authoritative. Otherwise the module
+// path is split into host, namespace and name: GitHub and Gitea paths are
+// host/owner/repo[/subdir], GitLab paths may nest groups, and any trailing
+// major version element is dropped. A module path on an unknown host (a
+// vanity import path) gives a Repository with no Forge or URL.
+//
+// When repoURL cannot be parsed, the error is returned together with the
+// Repository derived from the module path.
+func ParseRepository(modulePath, repoURL string) (Repository, error) {
+	repo := repositoryFromModule(modulePath)
+	if repoURL == "" {
+		return repo, nil
+	}
+	fromURL, err := repositoryFromURL(repoURL)
+	if err != nil {
+		return repo, err
+	}
+	return fromURL, nil
+}
+
+func repositoryFromModule(modulePath string) Repository {
+	parts := strings.Split(strings.Trim(modulePath, "/"), "/")
+	if n := len(parts); n > 2 && majorVersionSuffix.MatchString(parts[n-1]) {
+		parts = parts[:n-1]
+	}
+	if len(parts) < 2 {
+		return Repository{}
+	}
+	repo := Repository{Host: parts[0], Forge: forgeForHost(parts[0])}
+	switch {
+	case repo.Forge == ForgeGitHub || repo.Forge == ForgeGitea:
+		repo.Namespace = parts[1]
+		if len(parts) >= 3 {
+			repo.Name = parts[2]
+		}
+	default:
+		repo.Namespace = strings.Join(parts[1:len(parts)-1], "/")
+		repo.Name = parts[len(parts)-1]
+	}
+	if repo.Forge != "" && repo.Namespace != "" && repo.Name != "" {
+		repo.URL = "https://" + repo.Host + "/" + repo.Path()
+	}
+	return repo
+}
+
+func repositoryFromURL(raw string) (Repository, error) {
+	scheme, host, p := "https", "", ""
+	if strings.Contains(raw, "://") {
+		u, err := url.Parse(raw)
+		if err != nil {
+			return Repository{}, fmt.Errorf("invalid repository URL %q: %w", raw, err)
+		}
+		host, p = u.Host, u.Path
+		if u.Scheme == "http" {
+			scheme = "http"
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			host = u.Hostname() // the ssh port is not the web port
+		}
+	} else if at, rest, ok := strings.Cut(raw, "@"); ok && !strings.Contains(at, "/") {
+		// scp-like syntax: git@host:owner/repo.git
+		host, p, ok = strings.Cut(rest, ":")
+		if !ok {
+			return Repository{}, fmt.Errorf("invalid repository URL %q: expected user@host:path", raw)
+		}
+	} else {
+		return Repository{}, fmt.Errorf("invalid repository URL %q: expected https://host/owner/repo or git@host:owner/repo", raw)
+	}
+
+	p = strings.TrimSuffix(strings.Trim(p, "/"), ".git")
+	if host == "" || !strings.Contains(p, "/") {
+		return Repository{}, fmt.Errorf("invalid repository URL %q: expected an owner and a repository name", raw)
+	}
+	repo := Repository{
+		Forge:     forgeForHost(host),
+		Host:      host,
+		Namespace: path.Dir(p),
+		Name:      path.Base(p),
+	}
+	repo.URL = scheme + "://" + host + "/" + repo.Path()
+	return repo, nil
+}
+
+// Path returns the repository path on its host: "group/sub/my-app".
+func (r Repository) Path() string {
+	if r.Namespace == "" {
+		return r.Name
+	}
+	return r.Namespace + "/" + r.Name
+}
+
+// Owner returns the top-level element of the namespace: the user or
+// organization, or the top-level GitLab group.
+func (r Repository) Owner() string {
+	owner, _, _ := strings.Cut(r.Namespace, "/")
+	return owner
+}
+
+// PagesURL returns where the forge's static site hosting serves the
+// repository, or "" when it is not known (self-hosted instances).
+func (r Repository) PagesURL() string {
+	if r.Namespace == "" || r.Name == "" {
+		return ""
+	}
+	owner := strings.ToLower(r.Owner())
+	sub := strings.TrimPrefix(strings.TrimPrefix(r.Namespace, r.Owner()), "/")
+	if sub != "" {
+		sub += "/"
+	}
+	switch strings.ToLower(r.Host) {
+	case "github.com":
+		return fmt.Sprintf("https://%s.github.io/%s/", owner, r.Name)
+	case "gitlab.com":
+		return fmt.Sprintf("https://%s.gitlab.io/%s%s/", owner, sub, r.Name)
+	case "codeberg.org":
+		return fmt.Sprintf("https://%s.codeberg.page/%s/", owner, r.Name)
+	}
+	return ""
+}
+
+// Registry returns the container registry that belongs to the forge:
+// ghcr.io for github.com, registry.gitlab.com for gitlab.com, and the
+// conventional host of self-hosted instances. Without a known forge it is
+// DockerRegistry.
+func (r Repository) Registry() string {
+	host := strings.ToLower(r.Host)
+	switch r.Forge {
+	case ForgeGitHub:
+		if host == "github.com" {
+			return DockerRegistry
+		}
+		return "containers." + host
+	case ForgeGitLab:
+		return "registry." + host
+	case ForgeGitea:
+		return host
+	}
+	return DockerRegistry
+}
+
+// repository returns the configured project's Repository.
+func (s *Scaffolder) repository() (Repository, error) {
+	cfg := &s.Config
+	modulePath := cfg.GoModulePath
+	if modulePath == "" {
+		modulePath = DefaultModulePath(cfg.ModulePrefix, cfg.ProjectName)
+	}
+	return ParseRepository(modulePath, cfg.RepoURL)
+}
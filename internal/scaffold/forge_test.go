@@ -0,0 +1,121 @@
+package scaffold
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseRepository(t *testing.T) {
+	tests := []struct {
+		module, repoURL string
+		want            Repository
+	}{
+		{"github.com/joescharf/gsi", "",
+			Repository{ForgeGitHub, "github.com", "joescharf", "gsi", "https://github.com/joescharf/gsi"}},
+		{"github.com/acme/tools/cmd/app", "",
+			Repository{ForgeGitHub, "github.com", "acme", "tools", "https://github.com/acme/tools"}},
+		{"github.com/acme/app/v2", "",
+			Repository{ForgeGitHub, "github.com", "acme", "app", "https://github.com/acme/app"}},
+		{"gitlab.com/group/sub/app", "",
+			Repository{ForgeGitLab, "gitlab.com", "group/sub", "app", "https://gitlab.com/group/sub/app"}},
+		{"gitlab.acme.dev/platform/app/v3", "",
+			Repository{ForgeGitLab, "gitlab.acme.dev", "platform", "app", "https://gitlab.acme.dev/platform/app"}},
+		{"codeberg.org/acme/app", "",
+			Repository{ForgeGitea, "codeberg.org", "acme", "app", "https://codeberg.org/acme/app"}},
+		{"go.acme.dev/app", "",
+			Repository{"", "go.acme.dev", "", "app", ""}},
+		{"myapp", "", Repository{}},
+		{"go.acme.dev/app", "https://gitlab.com/acme/tools/app.git",
+			Repository{ForgeGitLab, "gitlab.com", "acme/tools", "app", "https://gitlab.com/acme/tools/app"}},
+		{"go.acme.dev/app", "git@github.com:Acme/app.git",
+			Repository{ForgeGitHub, "github.com", "Acme", "app", "https://github.com/Acme/app"}},
+		{"go.acme.dev/app", "ssh://git@git.acme.dev:2222/tools/app",
+			Repository{"", "git.acme.dev", "tools", "app", "https://git.acme.dev/tools/app"}},
+		{"go.acme.dev/app", "http://gitea.local:3000/tools/app/",
+			Repository{ForgeGitea, "gitea.local:3000", "tools", "app", "http://gitea.local:3000/tools/app"}},
+	}
+	for _, tt := range tests {
+		got, err := ParseRepository(tt.module, tt.repoURL)
+		if err != nil {
+			t.Errorf("ParseRepository(%q, %q): %v", tt.module, tt.repoURL, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseRepository(%q, %q) =\n  %+v\nwant\n  %+v", tt.module, tt.repoURL, got, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"github.com/acme/app", "https://github.com/app", "git@github.com", "ftp//x"} {
+		if _, err := ParseRepository("github.com/acme/app", bad); err == nil {
+			t.Errorf("expected an error for --repo-url %q", bad)
+		}
+	}
+}
+
+func TestRepositoryURLs(t *testing.T) {
+	tests := []struct {
+		repo            Repository
+		pages, registry string
+	}{
+		{Repository{ForgeGitHub, "github.com", "Acme", "app", ""}, "https://acme.github.io/app/", "ghcr.io"},
+		{Repository{ForgeGitHub, "github.acme.com", "acme", "app", ""}, "", "containers.github.acme.com"},
+		{Repository{ForgeGitLab, "gitlab.com", "group/sub", "app", ""}, "https://group.gitlab.io/sub/app/", "registry.gitlab.com"},
+		{Repository{ForgeGitLab, "gitlab.acme.dev", "group", "app", ""}, "", "registry.gitlab.acme.dev"},
+		{Repository{ForgeGitea, "codeberg.org", "acme", "app", ""}, "https://acme.codeberg.page/app/", "codeberg.org"},
+		{Repository{"", "go.acme.dev", "", "app", ""}, "", DockerRegistry},
+	}
+	for _, tt := range tests {
+		if got := tt.repo.PagesURL(); got != tt.pages {
+			t.Errorf("%+v PagesURL() = %q, want %q", tt.repo, got, tt.pages)
+		}
+		if got := tt.repo.Registry(); got != tt.registry {
+			t.Errorf("%+v Registry() = %q, want %q", tt.repo, got, tt.registry)
+		}
+	}
+}
+
+func TestTemplateDataRepository(t *testing.T) {
+	s, _, _ := testScaffolder(t, false)
+	s.Config.GoModulePath = "go.acme.dev/testproj"
+	s.Config.RepoURL = "https://gitlab.com/acme/tools/testproj"
+	data := s.templateData()
+	if data.Forge != ForgeGitLab || data.RepoOwner != "acme/tools" || data.GoModuleOwner != "acme" {
+		t.Errorf("unexpected repository data: %+v", data)
+	}
+	if data.DockerImage != "registry.gitlab.com/acme/tools/testproj" {
+		t.Errorf("DockerImage = %q", data.DockerImage)
+	}
+	if data.PagesURL != "https://acme.gitlab.io/tools/testproj/" {
+		t.Errorf("PagesURL = %q", data.PagesURL)
+	}
+}
+
+func TestStepPrintSummaryForge(t *testing.T) {
+	tests := []struct {
+		module, want, notWant string
+	}{
+		{"github.com/example/testproj", "gh repo create example/testproj", "glab"},
+		{"gitlab.com/group/sub/testproj", "glab repo create group/sub/testproj", "gh repo"},
+		{"go.acme.dev/testproj", "--repo-url", "gh repo"},
+	}
+	for _, tt := range tests {
+		s, stdout, _ := testScaffolder(t, false)
+		s.Config.GoModulePath = tt.module
+		s.stepPrintSummary()
+		if !strings.Contains(stdout.String(), tt.want) || strings.Contains(stdout.String(), tt.notWant) {
+			t.Errorf("%s: expected %q and not %q in summary, got %q", tt.module, tt.want, tt.notWant, stdout.String())
+		}
+	}
+}
+
+func TestStepGitHubPagesSkipsOtherForges(t *testing.T) {
+	s, stdout, _ := testScaffolder(t, false)
+	s.Config.GoModulePath = "gitlab.com/group/testproj"
+	s.Config.CI = CIGitHub
+	if err := runStep(t, s, "github-pages"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(stdout.String(), "not on GitHub") {
+		t.Errorf("expected skip message, got %q", stdout.String())
+	}
+}
@@ -0,0 +1,123 @@
+package scaffold
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// FS is the filesystem that file writers target. OSFS writes to disk; MemFS
+// keeps writes in memory so dry runs can render content without touching disk.
+type FS interface {
+	Stat(path string) (fs.FileInfo, error)
+	ReadFile(path string) ([]byte, error)
+	WriteFile(path string, data []byte, perm fs.FileMode) error
+	MkdirAll(path string, perm fs.FileMode) error
+}
+
+// OSFS is the real filesystem.
+type OSFS struct{}
+
+func (OSFS) Stat(path string) (fs.FileInfo, error) { return os.Stat(path) }
+func (OSFS) ReadFile(path string) ([]byte, error)  { return os.ReadFile(path) }
+func (OSFS) WriteFile(path string, data []byte, perm fs.FileMode) error {
+	return os.WriteFile(path, data, perm)
+}
+func (OSFS) MkdirAll(path string, perm fs.FileMode) error { return os.MkdirAll(path, perm) }
+
+// MemFS is an in-memory overlay: writes stay in memory while reads of paths
+// not written fall through to the base filesystem.
+type MemFS struct {
+	mu    sync.Mutex
+	base  FS
+	files map[string]memFile
+	dirs  map[string]bool
+}
+
+type memFile struct {
+	data []byte
+	mode fs.FileMode
+}
+
+// NewMemFS returns a MemFS layered over base. A nil base behaves as empty.
+func NewMemFS(base FS) *MemFS {
+	return &MemFS{base: base, files: map[string]memFile{}, dirs: map[string]bool{}}
+}
+
+func (m *MemFS) Stat(path string) (fs.FileInfo, error) {
+	path = filepath.Clean(path)
+	m.mu.Lock()
+	f, ok := m.files[path]
+	isDir := m.dirs[path]
+	m.mu.Unlock()
+	switch {
+	case ok:
+		return memInfo{name: filepath.Base(path), size: int64(len(f.data)), mode: f.mode}, nil
+	case isDir:
+		return memInfo{name: filepath.Base(path), mode: fs.ModeDir | 0o755}, nil
+	case m.base != nil:
+		return m.base.Stat(path)
+	}
+	return nil, &fs.PathError{Op: "stat", Path: path, Err: fs.ErrNotExist}
+}
+
+func (m *MemFS) ReadFile(path string) ([]byte, error) {
+	path = filepath.Clean(path)
+	m.mu.Lock()
+	f, ok := m.files[path]
+	m.mu.Unlock()
+	if ok {
+		return append([]byte(nil), f.data...), nil
+	}
+	if m.base != nil {
+		return m.base.ReadFile(path)
+	}
+	return nil, &fs.PathError{Op: "open", Path: path, Err: fs.ErrNotExist}
+}
+
+func (m *MemFS) WriteFile(path string, data []byte, perm fs.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.files[filepath.Clean(path)] = memFile{data: append([]byte(nil), data...), mode: perm}
+	return nil
+}
+
+func (m *MemFS) MkdirAll(path string, perm fs.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for p := filepath.Clean(path); ; p = filepath.Dir(p) {
+		m.dirs[p] = true
+		if parent := filepath.Dir(p); parent == p {
+			break
+		}
+	}
+	return nil
+}
+
+// Files returns the paths written to the MemFS in sorted order.
+func (m *MemFS) Files() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	paths := make([]string, 0, len(m.files))
+	for p := range m.files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+type memInfo struct {
+	name string
+	size int64
+	mode fs.FileMode
+}
+
+func (i memInfo) Name() string       { return i.name }
+func (i memInfo) Size() int64        { return i.size }
+func (i memInfo) Mode() fs.FileMode  { return i.mode }
+func (i memInfo) ModTime() time.Time { return time.Time{} }
+func (i memInfo) IsDir() bool        { return i.mode.IsDir() }
+func (i memInfo) Sys() any           { return nil }
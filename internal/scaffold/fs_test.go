@@ -0,0 +1,72 @@
+package scaffold
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMemFSOverlay(t *testing.T) {
+	dir := t.TempDir()
+	onDisk := filepath.Join(dir, "disk.txt")
+	if err := os.WriteFile(onDisk, []byte("disk"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewMemFS(OSFS{})
+
+	// Reads fall through to the base filesystem
+	got, err := m.ReadFile(onDisk)
+	if err != nil || string(got) != "disk" {
+		t.Fatalf("ReadFile fallthrough = %q, %v", got, err)
+	}
+
+	// Writes stay in memory
+	memPath := filepath.Join(dir, "sub", "mem.txt")
+	if err := m.MkdirAll(filepath.Dir(memPath), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.WriteFile(memPath, []byte("mem"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(memPath); err == nil {
+		t.Error("MemFS write should not reach disk")
+	}
+	info, err := m.Stat(memPath)
+	if err != nil {
+		t.Fatalf("Stat of written file failed: %v", err)
+	}
+	if info.Mode().Perm() != 0o755 || info.Size() != 3 {
+		t.Errorf("unexpected info mode=%v size=%d", info.Mode(), info.Size())
+	}
+	if info, err := m.Stat(filepath.Dir(memPath)); err != nil || !info.IsDir() {
+		t.Errorf("expected in-memory directory, got %v, %v", info, err)
+	}
+
+	// Overwriting a disk file shadows it without modifying it
+	if err := m.WriteFile(onDisk, []byte("shadow"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	got, _ = m.ReadFile(onDisk)
+	if string(got) != "shadow" {
+		t.Errorf("expected shadowed content, got %q", got)
+	}
+	disk, _ := os.ReadFile(onDisk)
+	if string(disk) != "disk" {
+		t.Errorf("disk content changed to %q", disk)
+	}
+
+	if files := m.Files(); len(files) != 2 {
+		t.Errorf("expected 2 in-memory files, got %v", files)
+	}
+}
+
+func TestMemFSNilBase(t *testing.T) {
+	m := NewMemFS(nil)
+	if _, err := m.Stat("/nope"); !os.IsNotExist(err) {
+		t.Errorf("expected not-exist error, got %v", err)
+	}
+	if _, err := m.ReadFile("/nope"); !os.IsNotExist(err) {
+		t.Errorf("expected not-exist error, got %v", err)
+	}
+}
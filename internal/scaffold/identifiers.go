@@ -0,0 +1,162 @@
+package scaffold
+
+import (
+	"fmt"
+	"go/token"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/joescharf/gsi/internal/templates"
+)
+
+// Identifiers are the names derived from a project name for the places that
+// restrict which characters they accept.
+type Identifiers struct {
+	BinaryName        string // the command name, as given: "my-app"
+	EnvPrefix         string // environment variable prefix: "MY_APP"
+	GoPackageName     string // Go package name: "myapp"
+	PythonPackageName string // Python package name: "my_app"
+	DockerImage       string // image in the forge's registry: "ghcr.io/owner/my-app"
+	DockerUser        string // container user and group: "my-app"
+}
+
+const (
+	// DockerRegistry is the registry the generated release pipeline pushes to.
+	DockerRegistry = "ghcr.io"
+
+	maxDockerUser = 32 // useradd/adduser limit on user names
+)
+
+var (
+	validBinaryName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+	validDockerUser = regexp.MustCompile(`^[a-z_][a-z0-9_-]*$`)
+	// validImagePath is one path component of a Docker image reference.
+	validImagePath = regexp.MustCompile(`^[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*$`)
+	// validPackageName is a lowercase Go or Python package name.
+	validPackageName = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+	// pythonSeparators are the runs PEP 503 normalisation collapses.
+	pythonSeparators = regexp.MustCompile(`[-_.]+`)
+)
+
+// pythonKeywords are the lowercase Python keywords, which cannot name a
+// package.
+var pythonKeywords = []string{
+	"and", "as", "assert", "async", "await", "break", "class", "continue",
+	"def", "del", "elif", "else", "except", "finally", "for", "from", "global",
+	"if", "import", "in", "is", "lambda", "nonlocal", "not", "or", "pass",
+	"raise", "return", "try", "while", "with", "yield",
+}
+
+// DeriveIdentifiers derives the project's identifiers from its name and
+// repository. Each is made legal where that only takes a change of case or
+// separators; ValidateIdentifiers reports the ones that cannot be.
+func DeriveIdentifiers(projectName string, repo Repository) Identifiers {
+	image := strings.ToLower(projectName)
+	if !validImagePath.MatchString(image) {
+		image = templates.Kebab(projectName)
+	}
+	if repo.Forge == ForgeGitLab && repo.Name != "" {
+		// GitLab only accepts images under the project's own path.
+		if strings.EqualFold(repo.Name, image) {
+			image = strings.ToLower(repo.Name)
+		} else {
+			image = strings.ToLower(repo.Name) + "/" + image
+		}
+	}
+	if repo.Namespace != "" {
+		image = strings.ToLower(repo.Namespace) + "/" + image
+	}
+
+	user := strings.ToLower(projectName)
+	if !validDockerUser.MatchString(user) {
+		user = leadingDigit(templates.Kebab(projectName))
+	}
+
+	goPkg := leadingDigit(strings.ReplaceAll(templates.Snake(projectName), "_", ""))
+	if token.IsKeyword(goPkg) {
+		goPkg += "_"
+	}
+	// PEP 503 normalisation, with "_" as the separator so it imports
+	pyPkg := leadingDigit(pythonSeparators.ReplaceAllString(strings.ToLower(projectName), "_"))
+	if slices.Contains(pythonKeywords, pyPkg) {
+		pyPkg += "_"
+	}
+
+	return Identifiers{
+		BinaryName:        projectName,
+		EnvPrefix:         templates.EnvVar(projectName),
+		GoPackageName:     goPkg,
+		PythonPackageName: pyPkg,
+		DockerImage:       repo.Registry() + "/" + image,
+		DockerUser:        user,
+	}
+}
+
+// DocsProjectName returns the name of the docs uv project: the Python
+// package name in PEP 503 normalised form, without the underscores added
+// for a leading digit or a keyword, followed by "-docs".
+func (id Identifiers) DocsProjectName() string {
+	return strings.Trim(strings.ReplaceAll(id.PythonPackageName, "_", "-"), "-") + "-docs"
+}
+
+// leadingDigit prefixes s with "_" when it starts with a digit, which most
+// identifier grammars reject.
+func leadingDigit(s string) string {
+	if s != "" && s[0] >= '0' && s[0] <= '9' {
+		return "_" + s
+	}
+	return s
+}
+
+// ValidateIdentifiers derives the project's identifiers and rejects a name
+// that cannot be made legal for every enabled capability: the binary name
+// must be a plain ASCII file name (which also makes EnvPrefix legal), the Go
+// package name must be legal, with docs so must the Python package name
+// (which also makes DocsProjectName legal), and with docker the image
+// reference and container user must be valid. With
+// docker and release on, the repository must have an owner, since the
+// release pipeline pushes the image under it; a bare module path needs
+// --module or a module-prefix for that, and a vanity one --repo-url.
+func ValidateIdentifiers(projectName string, repo Repository, caps map[string]bool) error {
+	id := DeriveIdentifiers(projectName, repo)
+	var problems []string
+	if !validBinaryName.MatchString(id.BinaryName) {
+		problems = append(problems, "the command name must start with a letter or digit and contain only ASCII letters, digits, '.', '_' and '-'")
+	}
+	if !validPackageName.MatchString(id.GoPackageName) || token.IsKeyword(id.GoPackageName) {
+		problems = append(problems, fmt.Sprintf("Go package name %q is not a valid identifier", id.GoPackageName))
+	}
+	if caps[CapDocs] && (!validPackageName.MatchString(id.PythonPackageName) || slices.Contains(pythonKeywords, id.PythonPackageName)) {
+		problems = append(problems, fmt.Sprintf("Python package name %q is not a valid identifier", id.PythonPackageName))
+	}
+	if caps[CapDocker] && len(problems) == 0 {
+		if caps[CapRelease] && repo.Namespace == "" {
+			problems = append(problems, fmt.Sprintf("Docker image %q has no owner to push to; pass --module or --repo-url, or set module-prefix with 'gsi config init'", id.DockerImage))
+		}
+		for _, part := range strings.Split(id.DockerImage, "/")[1:] {
+			if !validImagePath.MatchString(part) {
+				problems = append(problems, fmt.Sprintf("Docker image %q is not a valid reference (%q)", id.DockerImage, part))
+				break
+			}
+		}
+		if len(id.DockerUser) > maxDockerUser || !validDockerUser.MatchString(id.DockerUser) {
+			problems = append(problems, fmt.Sprintf("container user %q must be at most %d lowercase letters, digits, '_' and '-'", id.DockerUser, maxDockerUser))
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid project name %q: %s", projectName, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// validateIdentifiers runs ValidateIdentifiers for the configured project,
+// before anything is created for it. A --repo-url that does not parse is
+// reported here too.
+func (s *Scaffolder) validateIdentifiers() error {
+	repo, err := s.repository()
+	if err != nil {
+		return err
+	}
+	return ValidateIdentifiers(s.Config.ProjectName, repo, s.Config.Capabilities)
+}
@@ -0,0 +1,119 @@
+package scaffold
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDeriveIdentifiers(t *testing.T) {
+	github := func(owner string) Repository {
+		return Repository{Forge: ForgeGitHub, Host: "github.com", Namespace: owner, Name: "app"}
+	}
+	tests := []struct {
+		name string
+		repo Repository
+		want Identifiers
+	}{
+		{"my-app", github("acme"), Identifiers{"my-app", "MY_APP", "myapp", "my_app", "ghcr.io/acme/my-app", "my-app"}},
+		{"MyApp", github("JoeScharf"), Identifiers{"MyApp", "MY_APP", "myapp", "myapp", "ghcr.io/joescharf/myapp", "myapp"}},
+		{"my.app", Repository{}, Identifiers{"my.app", "MY_APP", "myapp", "my_app", "ghcr.io/my.app", "my-app"}},
+		{"My__App..cli", github("acme"), Identifiers{"My__App..cli", "MY_APP_CLI", "myappcli", "my_app_cli", "ghcr.io/acme/my-app-cli", "my-app-cli"}},
+		{"3d-tools", github("acme"), Identifiers{"3d-tools", "_3D_TOOLS", "_3dtools", "_3d_tools", "ghcr.io/acme/3d-tools", "_3d-tools"}},
+		{"select", github("acme"), Identifiers{"select", "SELECT", "select_", "select", "ghcr.io/acme/select", "select"}},
+		{"import", github("acme"), Identifiers{"import", "IMPORT", "import_", "import_", "ghcr.io/acme/import", "import"}},
+		{"app", Repository{Forge: ForgeGitLab, Host: "gitlab.com", Namespace: "Group/sub", Name: "app"},
+			Identifiers{"app", "APP", "app", "app", "registry.gitlab.com/group/sub/app", "app"}},
+		{"cli", Repository{Forge: ForgeGitLab, Host: "gitlab.com", Namespace: "group", Name: "tools"},
+			Identifiers{"cli", "CLI", "cli", "cli", "registry.gitlab.com/group/tools/cli", "cli"}},
+		{"app", Repository{Forge: ForgeGitea, Host: "codeberg.org", Namespace: "acme", Name: "app"},
+			Identifiers{"app", "APP", "app", "app", "codeberg.org/acme/app", "app"}},
+	}
+	for _, tt := range tests {
+		if got := DeriveIdentifiers(tt.name, tt.repo); got != tt.want {
+			t.Errorf("DeriveIdentifiers(%q, %+v) =\n  %+v\nwant\n  %+v", tt.name, tt.repo, got, tt.want)
+		}
+	}
+}
+
+func TestDocsProjectName(t *testing.T) {
+	for name, want := range map[string]string{
+		"my-app":   "my-app-docs",
+		"My.App":   "my-app-docs",
+		"3d-tools": "3d-tools-docs",
+		"import":   "import-docs",
+	} {
+		if got := DeriveIdentifiers(name, Repository{}).DocsProjectName(); got != want {
+			t.Errorf("DocsProjectName of %q = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestValidateIdentifiers(t *testing.T) {
+	docker := map[string]bool{CapDocker: true}
+	release := map[string]bool{CapDocker: true, CapRelease: true}
+	docs := map[string]bool{CapDocs: true}
+	long := strings.Repeat("a", 33)
+	tests := []struct {
+		name, owner string
+		caps        map[string]bool
+		wantErr     string // "" for valid
+	}{
+		{"my-app", "acme", docker, ""},
+		{"3d-tools", "acme", docker, ""},
+		{long, "acme", nil, ""},
+		{long, "acme", docker, "container user"},
+		{"app", "bad_", docker, "Docker image"},
+		{"app", "bad_", nil, ""},
+		{"app", "", docker, ""},
+		{"app", "", release, "no owner"},
+		{"app", "acme", release, ""},
+		{"my-app", "acme", docs, ""},
+		{"3d-tools", "acme", docs, ""},
+		{"my app", "acme", docs, "Python package name"},
+		{"-app", "acme", nil, "command name"},
+		{"my app", "acme", nil, "command name"},
+		{"café", "acme", nil, "Go package name"},
+		{"_", "acme", nil, "command name"},
+	}
+	for _, tt := range tests {
+		repo := Repository{Forge: ForgeGitHub, Host: "github.com", Namespace: tt.owner, Name: tt.name}
+		err := ValidateIdentifiers(tt.name, repo, tt.caps)
+		if tt.wantErr == "" && err != nil {
+			t.Errorf("%q: unexpected error %v", tt.name, err)
+		}
+		if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+			t.Errorf("%q: got %v, want an error about %s", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestRunRejectsInvalidName(t *testing.T) {
+	s, _, _ := testScaffolder(t, false)
+	s.Config.ProjectName = s.Config.ProjectDir + "/" + strings.Repeat("x", 40)
+	s.Config.Capabilities = map[string]bool{CapDocker: true}
+	if err := s.Run(); err == nil || !strings.Contains(err.Error(), "container user") {
+		t.Fatalf("expected the name to be rejected, got %v", err)
+	}
+}
+
+func TestDockerfileUsesIdentifiers(t *testing.T) {
+	s, _, _ := testScaffolder(t, false)
+	s.Config.ProjectName = "my-app"
+	s.Config.Capabilities = map[string]bool{CapDocker: true}
+	if err := runStep(t, s, "dockerfile"); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(s.Config.ProjectDir, "Dockerfile"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(content)
+	for _, want := range []string{"ENV MY_APP_DB_PATH=/data/my-app.db", "USER my-app", `ENTRYPOINT ["my-app"]`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in Dockerfile:\n%s", want, got)
+		}
+	}
+}
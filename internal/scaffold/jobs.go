@@ -0,0 +1,120 @@
+package scaffold
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"sync"
+)
+
+// DefaultJobs is how many steps gsi runs at once unless told otherwise. The
+// slow steps (npx, uv, bun, go mod tidy) mostly wait on the network.
+const DefaultJobs = 4
+
+// executeConcurrent runs the plan as a graph: each step starts once the
+// steps it comes after have finished, at most Config.Jobs at a time, in plan
+// order among those ready. Each step logs to its own buffer, printed whole
+// when the step finishes, so the output of steps running side by side is not
+// interleaved. Capabilities a step turns on or off are applied once it
+// finishes, so the steps after it see them. After a failure no further steps
+// start; those running are waited for.
+func (s *Scaffolder) executeConcurrent(plan []PlannedStep) error {
+	type result struct {
+		step  Step
+		err   error
+		merge func()
+	}
+	results := make(chan result)
+	finished := make(map[string]bool, len(plan))
+	pending := append([]PlannedStep(nil), plan...)
+	var logMu sync.Mutex
+	var firstErr error
+	running := 0
+
+	for {
+		for i := 0; firstErr == nil && running < s.Config.Jobs && i < len(pending); {
+			p := pending[i]
+			if !depsDone(p.Step, finished) {
+				i++
+				continue
+			}
+			pending = append(pending[:i:i], pending[i+1:]...)
+			if !s.ready(p) {
+				// Steps after this one may be ready now
+				finished[p.Name] = true
+				i = 0
+				continue
+			}
+			if err := s.interrupted(p); err != nil {
+				firstErr = err
+				break
+			}
+			run, flush, merge := s.stepCopy(p, &logMu)
+			running++
+			go func() {
+				err := run()
+				flush()
+				results <- result{p.Step, err, merge}
+			}()
+		}
+		if running == 0 {
+			break
+		}
+
+		r := <-results
+		running--
+		r.merge()
+		finished[r.step.Name] = true
+		s.saveCheckpoint(r.step, r.err)
+		if r.err != nil && firstErr == nil {
+			firstErr = r.err
+			s.pointToRunLog()
+		}
+	}
+	if firstErr == nil && len(pending) > 0 {
+		return fmt.Errorf("step %q never became ready", pending[0].Name)
+	}
+	return firstErr
+}
+
+// stepCopy prepares p to run on a copy of the Scaffolder with its own
+// logger, recorder view, executor and capability map. It returns a function
+// running the step on the copy, a flush that prints the step's output and
+// appends its commands to the run log, serialized by logMu, and a merge that
+// applies the capabilities the step changed to s. Merge must be called from
+// the goroutine scheduling the steps once run has returned.
+func (s *Scaffolder) stepCopy(p PlannedStep, logMu *sync.Mutex) (run func() error, flush func(), merge func()) {
+	log, flushLog := s.Logger.Buffer()
+	w := *s
+	w.Logger = log
+	before := maps.Clone(s.Config.Capabilities)
+	w.Config.Capabilities = maps.Clone(before)
+	w.Recorder = s.Recorder.ForStep(p.Name)
+	w.journal, w.state = nil, nil
+
+	e := *s.Executor
+	e.Logger, e.Recorder = log, w.Recorder
+	var commands bytes.Buffer
+	if e.Log != nil {
+		e.Log = &commands
+	}
+	w.Executor = &e
+
+	run = func() error { return p.Run(&w) }
+	flush = func() {
+		flushLog()
+		if commands.Len() > 0 {
+			logMu.Lock()
+			defer logMu.Unlock()
+			_, _ = s.Executor.Log.Write(commands.Bytes())
+		}
+	}
+	merge = func() {
+		for name, on := range w.Config.Capabilities {
+			if was, ok := before[name]; !ok || was != on {
+				s.Config.Capabilities[name] = on
+			}
+		}
+	}
+	return run, flush, merge
+}
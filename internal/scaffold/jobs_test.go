@@ -0,0 +1,203 @@
+package scaffold
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// concurrentScaffolder returns a test scaffolder that runs jobs steps at once.
+func concurrentScaffolder(t *testing.T, jobs int) (*Scaffolder, *strings.Builder) {
+	t.Helper()
+	s, stdout, _ := testScaffolder(t, false)
+	s.Config.Jobs = jobs
+	return s, stdout
+}
+
+func TestExecuteConcurrentRespectsAfter(t *testing.T) {
+	s, _ := concurrentScaffolder(t, 4)
+	var both sync.WaitGroup
+	both.Add(2)
+	var done sync.Map
+	// a and b only finish once both have started, so they must run side by side
+	together := func(name string) func(*Scaffolder) error {
+		return func(*Scaffolder) error {
+			both.Done()
+			wait := make(chan struct{})
+			go func() { both.Wait(); close(wait) }()
+			select {
+			case <-wait:
+			case <-time.After(5 * time.Second):
+				return fmt.Errorf("%s ran alone", name)
+			}
+			done.Store(name, true)
+			return nil
+		}
+	}
+	var cSawBoth bool
+	plan := []PlannedStep{
+		{Step: Step{Name: "a", Run: together("a")}},
+		{Step: Step{Name: "b", Run: together("b")}},
+		{Step: Step{Name: "c", After: []string{"a", "b"}, Run: func(*Scaffolder) error {
+			_, a := done.Load("a")
+			_, b := done.Load("b")
+			cSawBoth = a && b
+			return nil
+		}}},
+	}
+	if err := s.execute(plan); err != nil {
+		t.Fatal(err)
+	}
+	if !cSawBoth {
+		t.Error("c ran before the steps it comes after finished")
+	}
+}
+
+func TestExecuteConcurrentLimitsJobs(t *testing.T) {
+	s, _ := concurrentScaffolder(t, 2)
+	var running, most atomic.Int32
+	var plan []PlannedStep
+	for i := range 6 {
+		plan = append(plan, PlannedStep{Step: Step{Name: fmt.Sprint("step", i), Run: func(*Scaffolder) error {
+			n := running.Add(1)
+			defer running.Add(-1)
+			for {
+				m := most.Load()
+				if n <= m || most.CompareAndSwap(m, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			return nil
+		}}})
+	}
+	if err := s.execute(plan); err != nil {
+		t.Fatal(err)
+	}
+	if got := most.Load(); got > 2 {
+		t.Errorf("%d steps ran at once, want at most 2", got)
+	}
+}
+
+func TestExecuteConcurrentStopsAfterFailure(t *testing.T) {
+	s, _ := concurrentScaffolder(t, 4)
+	ran := false
+	plan := []PlannedStep{
+		{Step: Step{Name: "a", Description: "failing step", Run: func(*Scaffolder) error { return errors.New("boom") }}},
+		{Step: Step{Name: "b", After: []string{"a"}, Run: func(*Scaffolder) error { ran = true; return nil }}},
+	}
+	if err := s.execute(plan); err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("expected the step's error, got %v", err)
+	}
+	if ran {
+		t.Error("a step ran after the one it comes after failed")
+	}
+}
+
+func TestExecuteConcurrentKeepsOutputTogether(t *testing.T) {
+	s, stdout := concurrentScaffolder(t, 4)
+	var plan []PlannedStep
+	for i := range 4 {
+		name := fmt.Sprint("step", i)
+		plan = append(plan, PlannedStep{Step: Step{Name: name, Run: func(w *Scaffolder) error {
+			w.Logger.Info(name + " first")
+			time.Sleep(5 * time.Millisecond)
+			w.Logger.Info(name + " second")
+			w.Recorder.File(FileAction{Path: name, Action: ActionCreate})
+			return nil
+		}}})
+	}
+	if err := s.execute(plan); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	for i, line := range lines {
+		if first, ok := strings.CutSuffix(line, " first"); ok {
+			if i+1 == len(lines) || !strings.HasSuffix(lines[i+1], first+" second") {
+				t.Errorf("output of steps interleaved:\n%s", stdout)
+				break
+			}
+		}
+	}
+	for _, p := range plan {
+		if files := s.Recorder.StepFiles(p.Name); len(files) != 1 || files[0].Path != p.Name {
+			t.Errorf("%s: recorded files %+v, want its own file", p.Name, files)
+		}
+	}
+}
+
+func TestExecuteConcurrentKeepsCapabilityChanges(t *testing.T) {
+	s, _ := concurrentScaffolder(t, 4)
+	s.Config.Capabilities = map[string]bool{CapDocs: true, CapUI: true, CapDocker: true}
+	ranUI := false
+	plan := []PlannedStep{
+		{Step: Step{Name: "a", Run: func(w *Scaffolder) error { w.Config.Disable(CapDocs); return nil }}},
+		{Step: Step{Name: "b", Run: func(w *Scaffolder) error { w.Config.Disable(CapDocker); return nil }}},
+		{Step: Step{Name: "c", Capability: CapDocs, After: []string{"a"}, Run: func(*Scaffolder) error {
+			return errors.New("ran with docs disabled")
+		}}},
+		{Step: Step{Name: "d", Capability: CapUI, After: []string{"a", "b"}, Run: func(*Scaffolder) error {
+			ranUI = true
+			return nil
+		}}},
+	}
+	if err := s.execute(plan); err != nil {
+		t.Fatal(err)
+	}
+	if s.Config.IsEnabled(CapDocs) || s.Config.IsEnabled(CapDocker) || !ranUI {
+		t.Errorf("expected both disabled capabilities to stick and ui to run, got %v (ui ran: %v)", s.Config.Capabilities, ranUI)
+	}
+}
+
+func TestExecuteSerialUnderPrompt(t *testing.T) {
+	s, _ := concurrentScaffolder(t, 4)
+	s.Config.OnConflict = ConflictPrompt
+	var running, most atomic.Int32
+	step := func(*Scaffolder) error {
+		if n := running.Add(1); n > most.Load() {
+			most.Store(n)
+		}
+		time.Sleep(5 * time.Millisecond)
+		running.Add(-1)
+		return nil
+	}
+	plan := []PlannedStep{{Step: Step{Name: "a", Run: step}}, {Step: Step{Name: "b", Run: step}}}
+	if err := s.execute(plan); err != nil {
+		t.Fatal(err)
+	}
+	if most.Load() != 1 {
+		t.Errorf("%d steps ran at once while prompting, want 1", most.Load())
+	}
+}
+
+func TestRunConcurrent(t *testing.T) {
+	fail := fakeGo(t)
+	if err := os.Remove(fail); err != nil {
+		t.Fatal(err)
+	}
+	s, _ := concurrentScaffolder(t, 4)
+	dir := filepath.Join(s.Config.ProjectDir, "app")
+	s.Config.ProjectName = dir
+	s.Config.GoModulePath = ""
+	s.Config.GoVersion = "1.24"
+	s.Config.Capabilities = map[string]bool{CapDocker: true, CapMakefile: true}
+	if err := s.Run(); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	m, err := ReadManifest(OSFS{}, dir)
+	if err != nil || m == nil {
+		t.Fatalf("expected a manifest, got %v", err)
+	}
+	for _, path := range []string{"main.go", "Dockerfile", "Makefile"} {
+		if _, ok := m.File(path); !ok {
+			t.Errorf("expected %s in the manifest, got %+v", path, m.Files)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package scaffold
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// opaqueDirs are directories a journal records but does not look inside:
+// they are large, and tools only ever add to them.
+var opaqueDirs = []string{"node_modules", ".venv", filepath.Join(".git", "objects")}
+
+// journal records a project directory's state before a run so that a failed
+// run can be rolled back: paths created since are removed, and files changed
+// or deleted since are restored from copies taken when the journal began.
+type journal struct {
+	root    string
+	created bool                    // root did not exist; rolling back removes it
+	entries map[string]journalEntry // pre-existing paths relative to root
+	backup  string                  // copies of the pre-existing regular files
+}
+
+type journalEntry struct {
+	mode    fs.FileMode
+	size    int64
+	modTime time.Time
+	link    string // symlink target
+}
+
+// beginJournal snapshots root. Copies of its files go to a temporary
+// directory that commit or rollback removes.
+func beginJournal(root string) (*journal, error) {
+	j := &journal{root: root, entries: make(map[string]journalEntry)}
+	if _, err := os.Lstat(root); os.IsNotExist(err) {
+		j.created = true
+		return j, nil
+	}
+	backup, err := os.MkdirTemp("", "gsi-journal-")
+	if err != nil {
+		return nil, fmt.Errorf("creating rollback journal: %w", err)
+	}
+	j.backup = backup
+
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		e := journalEntry{mode: info.Mode(), size: info.Size(), modTime: info.ModTime()}
+		switch {
+		case d.IsDir():
+			j.entries[rel] = e
+			if isOpaque(rel) {
+				return filepath.SkipDir
+			}
+			return nil
+		case d.Type()&fs.ModeSymlink != 0:
+			if e.link, err = os.Readlink(path); err != nil {
+				return err
+			}
+		case d.Type().IsRegular():
+			if err := copyFile(path, filepath.Join(backup, rel), info.Mode().Perm()); err != nil {
+				return err
+			}
+		}
+		j.entries[rel] = e
+		return nil
+	})
+	if err != nil {
+		_ = os.RemoveAll(backup)
+		return nil, fmt.Errorf("creating rollback journal: %w", err)
+	}
+	return j, nil
+}
+
+// commit discards the journal, keeping the directory as it is.
+func (j *journal) commit() {
+	if j.backup != "" {
+		_ = os.RemoveAll(j.backup)
+	}
+}
+
+// rollback returns the directory to its state when the journal began and
+// discards the journal.
+func (j *journal) rollback() error {
+	defer j.commit()
+	if j.created {
+		return os.RemoveAll(j.root)
+	}
+
+	// Remove what is new, outermost first
+	err := filepath.WalkDir(j.root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		rel, err := filepath.Rel(j.root, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		e, existed := j.entries[rel]
+		if !existed || e.mode.Type() != d.Type() {
+			if err := os.RemoveAll(path); err != nil {
+				return err
+			}
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() && isOpaque(rel) {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Restore what changed or went missing, parents before children
+	rels := make([]string, 0, len(j.entries))
+	for rel := range j.entries {
+		rels = append(rels, rel)
+	}
+	slices.Sort(rels)
+	for _, rel := range rels {
+		if err := j.restore(rel, j.entries[rel]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restore puts one pre-existing path back unless it is unchanged.
+func (j *journal) restore(rel string, e journalEntry) error {
+	path := filepath.Join(j.root, rel)
+	info, err := os.Lstat(path)
+	switch {
+	case e.mode.IsDir():
+		if err != nil {
+			return os.MkdirAll(path, e.mode.Perm())
+		}
+		return nil
+	case e.mode&fs.ModeSymlink != 0:
+		if link, lerr := os.Readlink(path); lerr == nil && link == e.link {
+			return nil
+		}
+		_ = os.Remove(path)
+		return os.Symlink(e.link, path)
+	case !e.mode.IsRegular():
+		return nil
+	}
+	if err == nil && info.Size() == e.size && info.ModTime().Equal(e.modTime) && info.Mode() == e.mode {
+		return nil
+	}
+	if err := copyFile(filepath.Join(j.backup, rel), path, e.mode.Perm()); err != nil {
+		return err
+	}
+	if err := os.Chmod(path, e.mode.Perm()); err != nil {
+		return err
+	}
+	return os.Chtimes(path, e.modTime, e.modTime)
+}
+
+func isOpaque(rel string) bool {
+	return slices.Contains(opaqueDirs, rel) || slices.Contains(opaqueDirs, filepath.Base(rel))
+}
+
+// copyFile copies src to dst, creating dst's directory.
+func copyFile(src, dst string, perm fs.FileMode) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(dst, data, perm)
+}
@@ -0,0 +1,159 @@
+package scaffold
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestJournalRollback(t *testing.T) {
+	dir := t.TempDir()
+	for _, d := range []string{"keep", "gone", "node_modules"} {
+		if err := os.MkdirAll(filepath.Join(dir, d), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeTestFile(t, filepath.Join(dir, "go.mod"), "module example.com/app\n")
+	writeTestFile(t, filepath.Join(dir, "keep", "notes.txt"), "mine\n")
+	writeTestFile(t, filepath.Join(dir, "gone", "a.txt"), "a\n")
+	writeTestFile(t, filepath.Join(dir, "run.sh"), "#!/bin/sh\n")
+	if err := os.Chmod(filepath.Join(dir, "run.sh"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("go.mod", filepath.Join(dir, "link")); err != nil {
+		t.Fatal(err)
+	}
+
+	j, err := beginJournal(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// What a failed run might leave behind
+	writeTestFile(t, filepath.Join(dir, "go.mod"), "module example.com/app\n\nrequire x v1\n")
+	writeTestFile(t, filepath.Join(dir, "go.sum"), "x v1 h1:\n")
+	if err := os.MkdirAll(filepath.Join(dir, "cmd", "app"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, filepath.Join(dir, "cmd", "app", "main.go"), "package main\n")
+	writeTestFile(t, filepath.Join(dir, "node_modules", "dep.js"), "")
+	if err := os.RemoveAll(filepath.Join(dir, "gone")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(filepath.Join(dir, "run.sh"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(dir, "link")); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, filepath.Join(dir, "link"), "not a link\n")
+
+	if err := j.rollback(); err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range []string{"go.sum", "cmd"} {
+		if _, err := os.Stat(filepath.Join(dir, f)); !os.IsNotExist(err) {
+			t.Errorf("expected %s to be removed, got %v", f, err)
+		}
+	}
+	for path, want := range map[string]string{
+		"go.mod":         "module example.com/app\n",
+		"keep/notes.txt": "mine\n",
+		"gone/a.txt":     "a\n",
+	} {
+		if got, err := os.ReadFile(filepath.Join(dir, path)); err != nil || string(got) != want {
+			t.Errorf("%s = %q, %v; want %q", path, got, err, want)
+		}
+	}
+	if info, err := os.Stat(filepath.Join(dir, "run.sh")); err != nil || info.Mode().Perm() != 0o755 {
+		t.Errorf("expected run.sh to be executable again, got %v, %v", info.Mode(), err)
+	}
+	if link, err := os.Readlink(filepath.Join(dir, "link")); err != nil || link != "go.mod" {
+		t.Errorf("expected the symlink to be restored, got %q, %v", link, err)
+	}
+	// Pre-existing opaque directories are left alone
+	if _, err := os.Stat(filepath.Join(dir, "node_modules", "dep.js")); err != nil {
+		t.Errorf("expected node_modules to be untouched, got %v", err)
+	}
+	if _, err := os.Stat(j.backup); !os.IsNotExist(err) {
+		t.Errorf("expected the journal's copies to be removed, got %v", err)
+	}
+}
+
+func TestJournalRollbackCreatedDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "app")
+	j, err := beginJournal(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "cmd"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := j.rollback(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("expected %s to be removed, got %v", dir, err)
+	}
+}
+
+// interruptedRun runs a scaffold of a new directory whose context is already
+// cancelled, as after Ctrl-C.
+func interruptedRun(t *testing.T, keep bool) (string, error) {
+	t.Helper()
+	s, _, _ := testScaffolder(t, false)
+	dir := filepath.Join(s.Config.ProjectDir, "app")
+	s.Config.ProjectName = dir
+	s.Config.Capabilities = map[string]bool{CapDocker: true}
+	s.Config.KeepFailed = keep
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	s.Executor.Context = ctx
+	return dir, s.Run()
+}
+
+func TestRunRollsBackOnFailure(t *testing.T) {
+	dir, err := interruptedRun(t, false)
+	if err == nil || !strings.Contains(err.Error(), "interrupted") {
+		t.Fatalf("expected the run to be interrupted, got %v", err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("expected %s to be removed, got %v", dir, err)
+	}
+
+	dir, err = interruptedRun(t, true)
+	if err == nil {
+		t.Fatal("expected the run to fail")
+	}
+	if _, err := os.Stat(dir); err != nil {
+		t.Errorf("expected %s to be kept with KeepFailed, got %v", dir, err)
+	}
+}
+
+func TestAddRollsBackOnFailure(t *testing.T) {
+	s, _, _ := testScaffolder(t, false)
+	dir := s.Config.ProjectDir
+	writeGoMod(t, dir, "module github.com/acme/widget\n")
+	writeTestFile(t, filepath.Join(dir, "notes.txt"), "mine\n")
+	// A directory where the step writes a file makes it fail
+	if err := os.MkdirAll(filepath.Join(dir, ".dockerignore"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Add([]string{CapDocker}); err == nil {
+		t.Fatal("expected Add to fail")
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	if got := strings.Join(names, " "); got != ".dockerignore go.mod notes.txt" {
+		t.Errorf("expected only the pre-existing files after rollback, got %s", got)
+	}
+}
@@ -0,0 +1,237 @@
+package scaffold
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// ManifestName is the manifest file written to the root of every scaffolded
+// project.
+const ManifestName = ".gsi.yaml"
+
+// BaselineDir holds, relative to the project directory, the exact content
+// gsi last generated for each file in the manifest. gsi upgrade uses it as
+// the base of a three-way merge.
+const BaselineDir = ".gsi/baseline"
+
+// Manifest records what produced a project: the gsi version, the resolved
+// configuration, and every file gsi rendered with the hash of its content.
+// Later commands use it to tell gsi-owned files from user changes.
+type Manifest struct {
+	GSIVersion   string          `yaml:"gsi_version"`
+	Project      ManifestProject `yaml:"project"`
+	Capabilities map[string]bool `yaml:"capabilities"`
+	Packs        []ManifestPack  `yaml:"packs,omitempty"`
+	Files        []ManifestFile  `yaml:"files"`
+}
+
+// ManifestProject is the resolved project identity recorded in a Manifest.
+type ManifestProject struct {
+	Name        string `yaml:"name"`
+	ModulePath  string `yaml:"module_path"`
+	RepoURL     string `yaml:"repo_url,omitempty"`
+	Author      string `yaml:"author"`
+	Description string `yaml:"description,omitempty"`
+	License     string `yaml:"license,omitempty"`
+	Year        int    `yaml:"year,omitempty"`
+	GoVersion   string `yaml:"go_version,omitempty"`
+	CI          string `yaml:"ci,omitempty"`
+	Profile     string `yaml:"profile,omitempty"`
+	OnlyDocs    bool   `yaml:"only_docs,omitempty"`
+}
+
+// applyTo copies the recorded settings into cfg, keeping cfg's where the
+// manifest has none, so later commands render exactly as the first run did.
+func (p ManifestProject) applyTo(cfg *Config) {
+	if p.Author != "" {
+		cfg.Author = p.Author
+	}
+	if p.RepoURL != "" {
+		cfg.RepoURL = p.RepoURL
+	}
+	if p.Description != "" {
+		cfg.Description = p.Description
+	}
+	if p.License != "" {
+		cfg.License = p.License
+	}
+	if p.Year != 0 {
+		cfg.Year = p.Year
+	}
+	if p.GoVersion != "" {
+		cfg.GoVersion = p.GoVersion
+	}
+	if p.CI != "" {
+		cfg.CI = p.CI
+	}
+	cfg.Profile = p.Profile
+}
+
+// ManifestPack is a template pack used for the project. Commit pins a git
+// pack to the exact revision its templates came from.
+type ManifestPack struct {
+	Source string `yaml:"source"`
+	Ref    string `yaml:"ref,omitempty"`
+	Commit string `yaml:"commit,omitempty"`
+}
+
+// String formats p as a pack source, source@ref.
+func (p ManifestPack) String() string {
+	if p.Ref == "" {
+		return p.Source
+	}
+	return p.Source + "@" + p.Ref
+}
+
+// ManifestFile is a file gsi rendered. Hash is the hash of the content gsi
+// generated, so a file on disk with a different hash has been modified.
+type ManifestFile struct {
+	Path     string `yaml:"path"` // relative to the project directory, slash-separated
+	Template string `yaml:"template"`
+	Hash     string `yaml:"hash"`
+}
+
+// ContentHash returns the hash recorded for content in manifests and plans.
+func ContentHash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// ReadManifest loads the manifest of the project in dir. It returns nil and
+// no error when the project has no manifest.
+func ReadManifest(fsys FS, dir string) (*Manifest, error) {
+	data, err := fsys.ReadFile(filepath.Join(dir, ManifestName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", ManifestName, err)
+	}
+	var m Manifest
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", ManifestName, err)
+	}
+	return &m, nil
+}
+
+// File returns the entry for path (relative to the project directory).
+func (m *Manifest) File(path string) (ManifestFile, bool) {
+	if m == nil {
+		return ManifestFile{}, false
+	}
+	path = filepath.ToSlash(path)
+	for _, f := range m.Files {
+		if f.Path == path {
+			return f, true
+		}
+	}
+	return ManifestFile{}, false
+}
+
+// Modified reports whether content differs from what gsi generated for path.
+// Files not in the manifest are not owned by gsi and report false.
+func (m *Manifest) Modified(path string, content []byte) bool {
+	f, ok := m.File(path)
+	return ok && f.Hash != ContentHash(content)
+}
+
+// buildManifest assembles the manifest for the current run from the file
+// actions recorded so far. Files this run left alone keep their previous
+// entry, since what is on disk still derives from that earlier render.
+func (s *Scaffolder) buildManifest(prev *Manifest) *Manifest {
+	m := &Manifest{
+		GSIVersion:   s.gsiVersion(),
+		Project:      s.projectRecord(),
+		Capabilities: s.Config.Capabilities,
+		Packs:        s.packs,
+	}
+
+	files := map[string]ManifestFile{}
+	if prev != nil {
+		for _, f := range prev.Files {
+			files[f.Path] = f
+		}
+	}
+	for _, a := range s.Recorder.FileActions() {
+		if a.Template == "" {
+			continue
+		}
+		path := filepath.ToSlash(a.Path)
+		entry := ManifestFile{Path: path, Template: a.Template, Hash: a.Hash}
+		if _, kept := files[path]; kept && !writesGenerated(a.Action) {
+			continue
+		}
+		files[path] = entry
+	}
+
+	m.Files = make([]ManifestFile, 0, len(files))
+	for _, f := range files {
+		m.Files = append(m.Files, f)
+	}
+	sort.Slice(m.Files, func(i, j int) bool { return m.Files[i].Path < m.Files[j].Path })
+	return m
+}
+
+// gsiVersion returns the version recorded in manifests, "dev" for builds
+// without one.
+func (s *Scaffolder) gsiVersion() string {
+	if s.Config.Version == "" {
+		return "dev"
+	}
+	return s.Config.Version
+}
+
+// projectRecord returns the resolved project identity for the manifest.
+func (s *Scaffolder) projectRecord() ManifestProject {
+	cfg := s.Config
+	return ManifestProject{
+		Name:        cfg.ProjectName,
+		ModulePath:  cfg.GoModulePath,
+		RepoURL:     cfg.RepoURL,
+		Author:      cfg.Author,
+		Description: cfg.Description,
+		License:     cfg.License,
+		Year:        cfg.Year,
+		GoVersion:   cfg.GoVersion,
+		CI:          cfg.CI,
+		Profile:     cfg.Profile,
+		OnlyDocs:    cfg.OnlyDocs,
+	}
+}
+
+// writesGenerated reports whether a file action brings the generated content
+// onto disk, in full or (for merge) alongside the user's version.
+func writesGenerated(action string) bool {
+	switch action {
+	case ActionCreate, ActionOverwrite, ActionBackup, ActionMerge:
+		return true
+	}
+	return false
+}
+
+// loadManifest reads the manifest left by an earlier run, if any. A broken
+// manifest is reported and then replaced rather than blocking the run.
+func (s *Scaffolder) loadManifest() {
+	m, err := ReadManifest(s.FS, s.Config.ProjectDir)
+	if err != nil {
+		s.Logger.Warning(err.Error())
+	}
+	s.manifest = m
+}
+
+// stepWriteManifest records the run in the project's manifest.
+func (s *Scaffolder) stepWriteManifest() error {
+	path := filepath.Join(s.Config.ProjectDir, ManifestName)
+	data, err := yaml.Marshal(s.buildManifest(s.manifest))
+	if err != nil {
+		return fmt.Errorf("encoding %s: %w", ManifestName, err)
+	}
+	return s.writer().put(path, "", data, 0o644, ConflictOverwrite)
+}
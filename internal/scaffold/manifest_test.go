@@ -0,0 +1,147 @@
+package scaffold
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.yaml.in/yaml/v3"
+)
+
+func TestReadManifestMissing(t *testing.T) {
+	m, err := ReadManifest(OSFS{}, t.TempDir())
+	if err != nil || m != nil {
+		t.Errorf("expected no manifest and no error, got %+v, %v", m, err)
+	}
+}
+
+func TestReadManifestInvalid(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ManifestName), []byte("files: {"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadManifest(OSFS{}, dir); err == nil {
+		t.Error("expected a parse error")
+	}
+}
+
+func TestBuildPlanWritesManifest(t *testing.T) {
+	s := planScaffolder(t)
+	s.Config.Version = "1.2.3"
+	s.Config.Profile = "cli"
+	s.Config.Capabilities[CapDocker] = false
+
+	if _, err := s.BuildPlan(); err != nil {
+		t.Fatal(err)
+	}
+	m, err := ReadManifest(s.FS, s.Config.ProjectDir)
+	if err != nil || m == nil {
+		t.Fatalf("expected manifest in the dry-run FS, got %+v, %v", m, err)
+	}
+
+	if m.GSIVersion != "1.2.3" || m.Project.Name != filepath.Base(s.Config.ProjectDir) || m.Project.ModulePath != "github.com/example/testproj" || m.Project.Profile != "cli" {
+		t.Errorf("unexpected manifest header %+v", m)
+	}
+	if m.Capabilities[CapDocker] {
+		t.Error("expected docker disabled in recorded capabilities")
+	}
+
+	f, ok := m.File("cmd/root.go")
+	if !ok || f.Template != "cmd_root_go.tmpl" {
+		t.Fatalf("expected cmd/root.go entry, got %+v", f)
+	}
+	content, err := s.FS.ReadFile(filepath.Join(s.Config.ProjectDir, "cmd", "root.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Modified("cmd/root.go", content) {
+		t.Error("freshly generated file should not count as modified")
+	}
+	if !m.Modified("cmd/root.go", append(content, "// edit\n"...)) {
+		t.Error("edited file should count as modified")
+	}
+	if _, ok := m.File("Dockerfile"); ok {
+		t.Error("files from disabled capabilities should not be recorded")
+	}
+}
+
+func TestBuildManifestKeepsEntriesForUntouchedFiles(t *testing.T) {
+	s, _, _ := testScaffolder(t, true)
+	prev := &Manifest{Files: []ManifestFile{
+		{Path: "Makefile", Template: "makefile.tmpl", Hash: "sha256:old"},
+		{Path: "main.go", Template: "main_go.tmpl", Hash: "sha256:old"},
+	}}
+	s.Recorder.File(FileAction{Path: "Makefile", Action: ActionSkip, Template: "makefile.tmpl", Hash: "sha256:new"})
+	s.Recorder.File(FileAction{Path: "main.go", Action: ActionOverwrite, Template: "main_go.tmpl", Hash: "sha256:new"})
+	s.Recorder.File(FileAction{Path: "Dockerfile", Action: ActionSkip, Template: "dockerfile.tmpl", Hash: "sha256:new"})
+	s.Recorder.File(FileAction{Path: ManifestName, Action: ActionCreate})
+
+	m := s.buildManifest(prev)
+	want := map[string]string{"Dockerfile": "sha256:new", "Makefile": "sha256:old", "main.go": "sha256:new"}
+	if len(m.Files) != len(want) {
+		t.Fatalf("expected %d entries, got %+v", len(want), m.Files)
+	}
+	for _, f := range m.Files {
+		if want[f.Path] != f.Hash {
+			t.Errorf("%s: hash %q, want %q", f.Path, f.Hash, want[f.Path])
+		}
+	}
+	if m.GSIVersion != "dev" {
+		t.Errorf("expected dev version when unset, got %q", m.GSIVersion)
+	}
+}
+
+func TestFileWriterTreatsManifestMatchAsUnmodified(t *testing.T) {
+	log, _, _ := testLogger()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "package.json")
+	old := []byte("{}\n")
+	if err := os.WriteFile(path, old, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	m := &Manifest{Files: []ManifestFile{{Path: "package.json", Hash: ContentHash(old)}}}
+
+	w := &FileWriter{FS: OSFS{}, Root: dir, Logger: log, Manifest: m}
+	if err := w.WriteStatic(path, []byte(`{"name": "app"}`+"\n")); err != nil {
+		t.Fatal(err)
+	}
+	content, _ := os.ReadFile(path)
+	if string(content) == string(old) {
+		t.Error("file matching its manifest entry should be updated")
+	}
+}
+
+func TestManifestYAMLRoundTrip(t *testing.T) {
+	in := Manifest{
+		GSIVersion:   "1.0.0",
+		Project:      ManifestProject{Name: "app", ModulePath: "github.com/acme/app", Author: "A"},
+		Capabilities: map[string]bool{CapDocs: true},
+		Files:        []ManifestFile{{Path: "Makefile", Template: "makefile.tmpl", Hash: ContentHash([]byte("x"))}},
+	}
+	data, err := yaml.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out Manifest
+	if err := yaml.Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Files[0] != in.Files[0] || out.Project != in.Project {
+		t.Errorf("round trip mismatch: %+v", out)
+	}
+}
+
+func TestManifestProjectApplyTo(t *testing.T) {
+	cfg := Config{Author: "Flag Author", License: "ISC", Profile: "cli"}
+	ManifestProject{Author: "Recorded Author", Description: "Tool", Year: 2023, GoVersion: "1.22"}.applyTo(&cfg)
+
+	if cfg.Author != "Recorded Author" || cfg.Description != "Tool" || cfg.Year != 2023 || cfg.GoVersion != "1.22" {
+		t.Errorf("recorded settings not applied: %+v", cfg)
+	}
+	if cfg.License != "ISC" {
+		t.Errorf("license without a recorded value should be kept, got %q", cfg.License)
+	}
+	if cfg.Profile != "" {
+		t.Errorf("profile should follow the manifest, got %q", cfg.Profile)
+	}
+}
@@ -0,0 +1,113 @@
+package scaffold
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/joescharf/gsi/internal/templates"
+)
+
+// renamedSteps maps steps that no longer exist to the step that took their
+// place, so packs written for earlier gsi versions still order correctly.
+var renamedSteps = map[string]string{
+	"install-cobra-cli": "go-mod-init", // cmd/ is rendered from templates
+	"cobra-init":        "go-mod-init",
+}
+
+// packSteps turns each file declared in pack into a scaffold step that
+// renders the file's template. Capabilities are checked here rather than in
+// the templates package since only the scaffolder knows them.
+func (s *Scaffolder) packSteps(pack *templates.Pack) ([]Step, error) {
+	data := s.templateData()
+	steps := make([]Step, 0, len(pack.Files))
+	for _, f := range pack.Files {
+		if f.Capability != "" && !IsCapability(f.Capability) {
+			return nil, fmt.Errorf("pack %s: file %q has unknown capability %q (valid: %s)",
+				pack.Name, f.Name, f.Capability, strings.Join(CapabilityNames(), ", "))
+		}
+		if f.CI != "" && !IsCIProvider(f.CI) {
+			return nil, fmt.Errorf("pack %s: file %q has unknown CI provider %q (valid: %s, %s, %s)",
+				pack.Name, f.Name, f.CI, CIGitHub, CIGitLab, CIGitea)
+		}
+		rel, err := f.RenderPath(data)
+		if err != nil {
+			return nil, fmt.Errorf("pack %s: %w", pack.Name, err)
+		}
+		desc := f.Description
+		if desc == "" {
+			desc = rel
+		}
+		steps = append(steps, Step{
+			Name:        f.Name,
+			Description: desc,
+			Capability:  f.Capability,
+			CI:          f.CI,
+			Outputs:     []string{filepath.FromSlash(rel)},
+			After:       packAfter(f.After),
+			Docs:        f.Docs,
+			Inputs:      func(s *Scaffolder) any { return s.packFileInputs(f, rel) },
+			Run:         func(s *Scaffolder) error { return s.writePackFile(f, rel) },
+		})
+	}
+	return steps, nil
+}
+
+// packAfter returns after with renamed steps replaced.
+func packAfter(after []string) []string {
+	out := make([]string, 0, len(after))
+	for _, name := range after {
+		if to, ok := renamedSteps[name]; ok {
+			name = to
+		}
+		if !slices.Contains(out, name) {
+			out = append(out, name)
+		}
+	}
+	return out
+}
+
+// writePackFile renders one pack file to rel under the project directory,
+// honouring its mode and overwrite behavior.
+func (s *Scaffolder) writePackFile(f templates.PackFile, rel string) error {
+	mode, err := f.FileMode()
+	if err != nil {
+		return err
+	}
+	w := s.writer()
+	switch f.Overwrite {
+	case templates.OverwriteAlways:
+		w.Policy = ConflictOverwrite
+	case templates.OverwriteNever:
+		w.Policy = conflictKeep
+	}
+	return w.WriteTemplate(filepath.Join(s.Config.ProjectDir, filepath.FromSlash(rel)), f.Template, s.templateData(), mode)
+}
+
+// packFileInputs returns what writing f to rel depends on: where and how it
+// is written, and the content its template renders to. Hashing the content
+// rather than the settings makes a step depend on just the settings and
+// capabilities its template reads, the partials and overrides included.
+func (s *Scaffolder) packFileInputs(f templates.PackFile, rel string) any {
+	content, err := s.renderer.Render(f.Template, s.templateData())
+	if err != nil {
+		content = err.Error()
+	}
+	return []string{rel, f.Mode, f.Overwrite, ContentHash([]byte(content))}
+}
+
+// goSourceSteps returns the names of steps that write Go source files, which
+// go mod tidy must wait for.
+func goSourceSteps(steps []Step) []string {
+	var names []string
+	for _, step := range steps {
+		for _, out := range step.Outputs {
+			if filepath.Ext(out) == ".go" {
+				names = append(names, step.Name)
+				break
+			}
+		}
+	}
+	return names
+}
@@ -0,0 +1,151 @@
+package scaffold
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/joescharf/gsi/internal/templates"
+)
+
+func TestPackStepsFromCustomPack(t *testing.T) {
+	s, _, _ := testScaffolder(t, false)
+	pack, err := templates.ParsePack([]byte(`
+name: custom
+files:
+  - name: notes
+    path: "docs/{{.ProjectName}}.md"
+    template: docs_index_md.tmpl
+  - name: script
+    path: run.sh
+    template: docs_scripts_scrape_sh.tmpl
+    mode: "0755"
+    capability: docs
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Pack = pack
+
+	for _, name := range []string{"notes", "script"} {
+		if err := runStep(t, s, name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(s.Config.ProjectDir, "docs", "testproj.md")); err != nil {
+		t.Errorf("expected templated path to be written: %v", err)
+	}
+	info, err := os.Stat(filepath.Join(s.Config.ProjectDir, "run.sh"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o755 {
+		t.Errorf("run.sh mode = %v, want 0755", info.Mode().Perm())
+	}
+}
+
+func TestPackStepsUnknownCapability(t *testing.T) {
+	s, _, _ := testScaffolder(t, false)
+	s.Pack = &templates.Pack{Name: "custom", Files: []templates.PackFile{
+		{Name: "a", Path: "a", Template: "gitignore.tmpl", Capability: "nope"},
+	}}
+	if _, err := s.Plan(); err == nil || !strings.Contains(err.Error(), "unknown capability") {
+		t.Errorf("expected unknown capability error, got %v", err)
+	}
+}
+
+func TestPackStepsUnknownCI(t *testing.T) {
+	s, _, _ := testScaffolder(t, false)
+	s.Pack = &templates.Pack{Name: "custom", Files: []templates.PackFile{
+		{Name: "a", Path: "a", Template: "gitignore.tmpl", CI: "jenkins"},
+	}}
+	if _, err := s.Plan(); err == nil || !strings.Contains(err.Error(), "unknown CI provider") {
+		t.Errorf("expected unknown CI provider error, got %v", err)
+	}
+}
+
+func TestPackStepsRenamedAfter(t *testing.T) {
+	s, _, _ := testScaffolder(t, false)
+	s.Pack = &templates.Pack{Name: "custom", Files: []templates.PackFile{
+		{Name: "a", Path: "a.go", Template: "main_go.tmpl", After: []string{"install-cobra-cli", "cobra-init"}},
+	}}
+	plan, err := s.Plan()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range plan {
+		if p.Name == "a" && strings.Join(p.After, ",") != "go-mod-init" {
+			t.Errorf("After = %v, want [go-mod-init]", p.After)
+		}
+	}
+}
+
+func TestPackFileOverwrite(t *testing.T) {
+	tests := []struct {
+		overwrite string
+		keep      bool
+	}{
+		{templates.OverwriteConflict, true},
+		{templates.OverwriteNever, true},
+		{templates.OverwriteAlways, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.overwrite, func(t *testing.T) {
+			s, _, _ := testScaffolder(t, false)
+			s.Pack = &templates.Pack{Name: "custom", Files: []templates.PackFile{
+				{Name: "ignore", Path: ".gitignore", Template: "gitignore.tmpl", Overwrite: tt.overwrite},
+			}}
+			path := filepath.Join(s.Config.ProjectDir, ".gitignore")
+			if err := os.WriteFile(path, []byte("mine\n"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if err := runStep(t, s, "ignore"); err != nil {
+				t.Fatal(err)
+			}
+			got, _ := os.ReadFile(path)
+			if kept := string(got) == "mine\n"; kept != tt.keep {
+				t.Errorf("kept = %v, want %v", kept, tt.keep)
+			}
+		})
+	}
+}
+
+func TestPackFileNeverKeepsUnmodified(t *testing.T) {
+	s, _, _ := testScaffolder(t, false)
+	path := filepath.Join(s.Config.ProjectDir, ".gitignore")
+	// A stamped, unedited file would normally be refreshed
+	if err := os.WriteFile(path, stampContent(path, []byte("old\n")), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	s.Pack = &templates.Pack{Name: "custom", Files: []templates.PackFile{
+		{Name: "ignore", Path: ".gitignore", Template: "gitignore.tmpl", Overwrite: templates.OverwriteNever},
+	}}
+	if err := runStep(t, s, "ignore"); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := os.ReadFile(path)
+	if !strings.Contains(string(got), "old") {
+		t.Error("overwrite: never should leave an existing file alone")
+	}
+}
+
+func TestGoModTidyWaitsForGoSources(t *testing.T) {
+	s, _, _ := testScaffolder(t, false)
+	steps, err := s.Steps()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, step := range steps {
+		if step.Name != "go-mod-tidy" {
+			continue
+		}
+		for _, want := range []string{"main-go", "root-cmd", "config-pkg", "embed-go"} {
+			if !strings.Contains(strings.Join(step.After, ","), want) {
+				t.Errorf("go-mod-tidy should run after %s; After = %v", want, step.After)
+			}
+		}
+		return
+	}
+	t.Fatal("no go-mod-tidy step")
+}
@@ -0,0 +1,85 @@
+package scaffold
+
+import (
+	"github.com/joescharf/gsi/internal/packs"
+	"github.com/joescharf/gsi/internal/templates"
+)
+
+// loadPacks resolves Config.Packs, layering their templates over the
+// built-in ones and their pack manifests over the built-in pack. Packs listed
+// first take precedence. Git packs are fetched into Config.PackCacheDir, or
+// used as last fetched under --offline.
+func (s *Scaffolder) loadPacks() error {
+	s.packs = nil
+	s.renderer = &templates.Renderer{Overrides: s.Config.TemplateDirs}
+	if len(s.Config.Packs) == 0 {
+		return nil
+	}
+
+	fetcher := &packs.Fetcher{CacheDir: s.Config.PackCacheDir, Offline: s.Config.Offline}
+	loaded := make([]*packs.Loaded, 0, len(s.Config.Packs))
+	dirs := make([]string, 0, len(s.Config.Packs))
+	for _, raw := range s.Config.Packs {
+		s.Logger.VerboseMsg("Loading template pack " + raw)
+		l, err := fetcher.Load(raw)
+		if err != nil {
+			return err
+		}
+		loaded = append(loaded, l)
+		dirs = append(dirs, l.Dir)
+		s.packs = append(s.packs, ManifestPack{Source: l.Source.Location, Ref: l.Source.Ref, Commit: l.Commit})
+	}
+
+	pack := s.Pack
+	if pack == nil {
+		var err error
+		if pack, err = templates.BuiltinPack(); err != nil {
+			return err
+		}
+	}
+	for i := len(loaded) - 1; i >= 0; i-- {
+		pack = pack.Merge(loaded[i].Pack)
+	}
+	s.Pack = pack
+	s.renderer = &templates.Renderer{Overrides: s.Config.TemplateDirs, Packs: dirs}
+	return nil
+}
+
+// TemplateRenderer loads the configured packs and returns the renderer
+// templates are read through: override directories first, then the packs,
+// then the built-ins.
+func (s *Scaffolder) TemplateRenderer() (*templates.Renderer, error) {
+	if err := s.loadPacks(); err != nil {
+		return nil, err
+	}
+	return s.renderer, nil
+}
+
+// usePacksFrom falls back to the packs recorded in m when none were given,
+// so later commands on a project keep rendering from the same packs.
+func (s *Scaffolder) usePacksFrom(m *Manifest) {
+	if len(s.Config.Packs) > 0 || m == nil {
+		return
+	}
+	for _, p := range m.Packs {
+		s.Config.Packs = append(s.Config.Packs, p.String())
+	}
+}
+
+// printPacks lists the loaded packs in the configuration summary.
+func (s *Scaffolder) printPacks() {
+	for _, p := range s.packs {
+		line := "  Pack:          " + p.String()
+		if p.Commit != "" {
+			line += " (" + shortCommit(p.Commit) + ")"
+		}
+		s.Logger.Plain(line)
+	}
+}
+
+func shortCommit(commit string) string {
+	if len(commit) > 12 {
+		return commit[:12]
+	}
+	return commit
+}
@@ -0,0 +1,97 @@
+package scaffold
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/joescharf/gsi/internal/templates"
+)
+
+func TestLoadPacksLayersTemplatesAndFiles(t *testing.T) {
+	pack := t.TempDir()
+	writeTestFile(t, filepath.Join(pack, "dockerfile.tmpl"), "FROM acme/base\n")
+	writeTestFile(t, filepath.Join(pack, "lint.tmpl"), "project: {{.ProjectName}}\n")
+	writeTestFile(t, filepath.Join(pack, templates.PackFileName),
+		"name: acme\nfiles:\n  - {name: acme-lint, description: acme lint config, path: .acme-lint.yml, template: lint.tmpl}\n")
+
+	s, _, _ := testScaffolder(t, false)
+	s.Config.Packs = []string{pack}
+	if err := s.loadPacks(); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"dockerfile", "acme-lint"} {
+		if err := runStep(t, s, name); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, _ := os.ReadFile(filepath.Join(s.Config.ProjectDir, "Dockerfile"))
+	if !strings.Contains(string(got), "FROM acme/base") {
+		t.Errorf("expected pack Dockerfile, got %q", got)
+	}
+	got, _ = os.ReadFile(filepath.Join(s.Config.ProjectDir, ".acme-lint.yml"))
+	if !strings.Contains(string(got), "project: testproj") {
+		t.Errorf("expected pack file, got %q", got)
+	}
+
+	m := s.buildManifest(nil)
+	if len(m.Packs) != 1 || m.Packs[0].Source != pack {
+		t.Errorf("manifest packs = %+v", m.Packs)
+	}
+}
+
+func TestPacksAreScopedToTheScaffolder(t *testing.T) {
+	pack := t.TempDir()
+	writeTestFile(t, filepath.Join(pack, "dockerfile.tmpl"), "FROM acme/base\n")
+	withPack, _, _ := testScaffolder(t, false)
+	withPack.Config.Packs = []string{pack}
+	if err := withPack.loadPacks(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Another scaffolder in the same process keeps the built-in template
+	s, _, _ := testScaffolder(t, false)
+	if err := runStep(t, s, "dockerfile"); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := os.ReadFile(filepath.Join(s.Config.ProjectDir, "Dockerfile"))
+	if strings.Contains(string(got), "FROM acme/base") {
+		t.Errorf("a pack loaded by another scaffolder leaked into this one:\n%s", got)
+	}
+}
+
+func TestUsePacksFromManifest(t *testing.T) {
+	s, _, _ := testScaffolder(t, false)
+	m := &Manifest{Packs: []ManifestPack{
+		{Source: "https://example.com/pack.git", Ref: "v1", Commit: "abc"},
+		{Source: "/srv/pack"},
+	}}
+	s.usePacksFrom(m)
+	if strings.Join(s.Config.Packs, " ") != "https://example.com/pack.git@v1 /srv/pack" {
+		t.Errorf("Packs = %v", s.Config.Packs)
+	}
+
+	// Packs given explicitly win
+	s.Config.Packs = []string{"/other"}
+	s.usePacksFrom(m)
+	if len(s.Config.Packs) != 1 {
+		t.Errorf("explicit packs replaced: %v", s.Config.Packs)
+	}
+}
+
+func TestLoadPacksMissing(t *testing.T) {
+	s, _, _ := testScaffolder(t, false)
+	s.Config.Packs = []string{filepath.Join(t.TempDir(), "missing")}
+	if err := s.loadPacks(); err == nil {
+		t.Error("expected error for a missing pack")
+	}
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
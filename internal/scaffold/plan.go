@@ -0,0 +1,254 @@
+package scaffold
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+)
+
+// File actions recorded for a plan.
+const (
+	ActionCreate    = "create"
+	ActionOverwrite = "overwrite"
+	ActionSkip      = "skip"
+	ActionBackup    = "backup" // existing file saved as <path>.orig, then overwritten
+	ActionMerge     = "merge"  // written with conflict markers
+	ActionPrompt    = "prompt" // the user will be asked
+)
+
+// FileAction describes what a run does, or would do, to a single file.
+type FileAction struct {
+	Path     string `json:"path" yaml:"path"` // relative to the project directory
+	Action   string `json:"action" yaml:"action"`
+	Template string `json:"template,omitempty" yaml:"template,omitempty"`
+	Mode     string `json:"mode,omitempty" yaml:"mode,omitempty"`
+	Hash     string `json:"hash,omitempty" yaml:"hash,omitempty"` // of the content gsi generates
+	Step     string `json:"step,omitempty" yaml:"step,omitempty"`
+}
+
+// CommandAction describes an external command the Executor runs, or would run.
+type CommandAction struct {
+	Command     string `json:"command" yaml:"command"`
+	Dir         string `json:"dir" yaml:"dir"`
+	Description string `json:"description" yaml:"description"`
+	Step        string `json:"step,omitempty" yaml:"step,omitempty"`
+}
+
+// Recorder collects file and command actions as steps execute.
+type Recorder struct {
+	mu       sync.Mutex
+	step     string
+	parent   *Recorder // set on the views returned by ForStep
+	Files    []FileAction
+	Commands []CommandAction
+}
+
+// ForStep returns a view of r that attributes the actions recorded through
+// it to the named step, for steps running concurrently. It returns nil for a
+// nil Recorder.
+func (r *Recorder) ForStep(name string) *Recorder {
+	if r == nil {
+		return nil
+	}
+	return &Recorder{step: name, parent: r}
+}
+
+// FileActions returns a copy of the file actions recorded so far, through r
+// or any view of it.
+func (r *Recorder) FileActions() []FileAction {
+	if r == nil {
+		return nil
+	}
+	if r.parent != nil {
+		return r.parent.FileActions()
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.Files)
+}
+
+// StepFiles returns the file actions recorded for the named step.
+func (r *Recorder) StepFiles(name string) []FileAction {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var files []FileAction
+	for _, a := range r.Files {
+		if a.Step == name {
+			files = append(files, a)
+		}
+	}
+	return files
+}
+
+// SetStep attributes subsequently recorded actions to the named step.
+func (r *Recorder) SetStep(name string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.step = name
+}
+
+// File records a file action. It is a no-op on a nil Recorder.
+func (r *Recorder) File(a FileAction) {
+	if r == nil {
+		return
+	}
+	if r.parent != nil {
+		if a.Step == "" {
+			a.Step = r.step
+		}
+		r.parent.File(a)
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if a.Step == "" {
+		a.Step = r.step
+	}
+	r.Files = append(r.Files, a)
+}
+
+// Command records a command action. It is a no-op on a nil Recorder.
+func (r *Recorder) Command(a CommandAction) {
+	if r == nil {
+		return
+	}
+	if r.parent != nil {
+		if a.Step == "" {
+			a.Step = r.step
+		}
+		r.parent.Command(a)
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if a.Step == "" {
+		a.Step = r.step
+	}
+	r.Commands = append(r.Commands, a)
+}
+
+// PlanProject is the resolved project identity in a PlanDocument.
+type PlanProject struct {
+	Name       string `json:"name" yaml:"name"`
+	Dir        string `json:"dir" yaml:"dir"`
+	ModulePath string `json:"module_path" yaml:"module_path"`
+	Author     string `json:"author" yaml:"author"`
+	Profile    string `json:"profile,omitempty" yaml:"profile,omitempty"`
+	OnlyDocs   bool   `json:"only_docs" yaml:"only_docs"`
+}
+
+// PlanStepEntry is a registered step and whether it will run.
+type PlanStepEntry struct {
+	Name       string   `json:"name" yaml:"name"`
+	Capability string   `json:"capability,omitempty" yaml:"capability,omitempty"`
+	Run        bool     `json:"run" yaml:"run"`
+	Reason     string   `json:"reason,omitempty" yaml:"reason,omitempty"`
+	Tools      []string `json:"tools,omitempty" yaml:"tools,omitempty"`
+	Outputs    []string `json:"outputs,omitempty" yaml:"outputs,omitempty"`
+}
+
+// PlanDocument is the machine-readable description of a scaffold run.
+type PlanDocument struct {
+	Project      PlanProject     `json:"project" yaml:"project"`
+	Capabilities map[string]bool `json:"capabilities" yaml:"capabilities"`
+	Steps        []PlanStepEntry `json:"steps" yaml:"steps"`
+	Files        []FileAction    `json:"files" yaml:"files"`
+	Commands     []CommandAction `json:"commands" yaml:"commands"`
+}
+
+// BuildPlan performs a silent dry run and returns everything it would do.
+// Log output is discarded; errors are returned as usual.
+func (s *Scaffolder) BuildPlan() (*PlanDocument, error) {
+	s.Config.DryRun = true
+	s.Executor.DryRun = true
+	s.FS = NewMemFS(OSFS{})
+	s.Recorder = &Recorder{}
+	s.Executor.Recorder = s.Recorder
+	s.Logger.Stdout = io.Discard
+	s.Logger.Stderr = io.Discard
+
+	if err := s.Run(); err != nil {
+		return nil, err
+	}
+
+	plan, err := s.Plan()
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := s.Config
+	doc := &PlanDocument{
+		Project: PlanProject{
+			Name:       cfg.ProjectName,
+			Dir:        cfg.ProjectDir,
+			ModulePath: cfg.GoModulePath,
+			Author:     cfg.Author,
+			Profile:    cfg.Profile,
+			OnlyDocs:   cfg.OnlyDocs,
+		},
+		Capabilities: cfg.Capabilities,
+		Files:        s.Recorder.Files,
+		Commands:     s.Recorder.Commands,
+	}
+	doc.Steps = s.stepEntries(plan)
+	if doc.Files == nil {
+		doc.Files = []FileAction{}
+	}
+	if doc.Commands == nil {
+		doc.Commands = []CommandAction{}
+	}
+	for i := range doc.Commands {
+		doc.Commands[i].Dir = s.relPath(doc.Commands[i].Dir)
+	}
+	return doc, nil
+}
+
+// stepEntries describes the steps of plan as the dry run executed them: a
+// step the run held back, say because its tool is missing, does not run.
+func (s *Scaffolder) stepEntries(plan []PlannedStep) []PlanStepEntry {
+	entries := make([]PlanStepEntry, 0, len(plan))
+	for _, p := range plan {
+		e := PlanStepEntry{
+			Name:       p.Name,
+			Capability: p.Capability,
+			Run:        !p.Skip,
+			Reason:     p.Reason,
+			Tools:      p.Tools,
+			Outputs:    p.Outputs,
+		}
+		if reason, ok := s.heldBack[p.Name]; ok && e.Run {
+			e.Run, e.Reason = false, reason
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+// relPath returns path relative to the project directory when possible.
+func (s *Scaffolder) relPath(path string) string {
+	return relTo(s.Config.ProjectDir, path)
+}
+
+func relTo(base, path string) string {
+	if base == "" {
+		return path
+	}
+	rel, err := filepath.Rel(base, path)
+	if err != nil {
+		return path
+	}
+	return filepath.ToSlash(rel)
+}
+
+func fileModeString(mode os.FileMode) string {
+	return fmt.Sprintf("%04o", uint32(mode.Perm()))
+}
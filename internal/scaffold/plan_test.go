@@ -0,0 +1,131 @@
+package scaffold
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func planScaffolder(t *testing.T) *Scaffolder {
+	t.Helper()
+	s, _, _ := testScaffolder(t, false)
+	s.Config.ProjectName = s.Config.ProjectDir
+	// Keep the plan independent of tools installed on the test host
+	for _, name := range []string{CapBmad, CapDocs, CapGit, CapUI} {
+		s.Config.Capabilities[name] = false
+	}
+	return s
+}
+
+func TestBuildPlan(t *testing.T) {
+	s := planScaffolder(t)
+	s.Config.Capabilities[CapDocker] = false
+	if err := os.WriteFile(filepath.Join(s.Config.ProjectDir, ".editorconfig"), []byte("custom"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	doc, err := s.BuildPlan()
+	if err != nil {
+		t.Fatalf("BuildPlan failed: %v", err)
+	}
+
+	if doc.Project.ModulePath != "github.com/example/testproj" {
+		t.Errorf("unexpected module path %q", doc.Project.ModulePath)
+	}
+	if doc.Capabilities[CapDocker] {
+		t.Error("expected docker disabled in plan capabilities")
+	}
+
+	files := map[string]FileAction{}
+	for _, f := range doc.Files {
+		files[f.Path] = f
+	}
+	if f := files["main.go"]; f.Action != ActionCreate || f.Template != "main_go.tmpl" || f.Step != "main-go" {
+		t.Errorf("unexpected main.go action %+v", f)
+	}
+	if f := files[".editorconfig"]; f.Action != ActionSkip {
+		t.Errorf("expected existing .editorconfig to be skipped, got %+v", f)
+	}
+	if _, ok := files["Dockerfile"]; ok {
+		t.Error("Dockerfile should not appear when docker is disabled")
+	}
+
+	var tidy *CommandAction
+	for i := range doc.Commands {
+		if doc.Commands[i].Command == "go mod tidy" {
+			tidy = &doc.Commands[i]
+		}
+	}
+	if tidy == nil || tidy.Dir != "." || tidy.Step != "go-mod-tidy" {
+		t.Errorf("expected go mod tidy in project dir, got %+v", tidy)
+	}
+
+	for _, step := range doc.Steps {
+		if step.Name == "dockerfile" && (step.Run || step.Reason != "--no-docker") {
+			t.Errorf("unexpected dockerfile step entry %+v", step)
+		}
+	}
+
+	// A plan never touches the project directory
+	if _, err := os.Stat(filepath.Join(s.Config.ProjectDir, "main.go")); err == nil {
+		t.Error("BuildPlan should not create files")
+	}
+}
+
+func TestStepEntriesHeldBack(t *testing.T) {
+	s, _, _ := testScaffolder(t, true)
+	plan := []PlannedStep{
+		{Step: Step{Name: "needs-tool", Tools: []string{"nonexistent_command_xyz_12345"}, Run: func(*Scaffolder) error { return nil }}},
+		{Step: Step{Name: "plain", Run: func(*Scaffolder) error { return nil }}},
+	}
+	if err := s.execute(plan); err != nil {
+		t.Fatal(err)
+	}
+	entries := s.stepEntries(plan)
+	if e := entries[0]; e.Run || e.Reason != "nonexistent_command_xyz_12345 not found" {
+		t.Errorf("expected the step without its tool to be skipped, got %+v", e)
+	}
+	if e := entries[1]; !e.Run || e.Reason != "" {
+		t.Errorf("expected the other step to run, got %+v", e)
+	}
+}
+
+func TestBuildPlanExistingFile(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy ConflictPolicy
+		want   string
+	}{
+		{"default", "", ActionSkip},
+		{"overwrite", ConflictOverwrite, ActionOverwrite},
+		{"backup", ConflictBackup, ActionBackup},
+		{"merge", ConflictMerge, ActionMerge},
+		{"prompt", ConflictPrompt, ActionPrompt},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := planScaffolder(t)
+			s.Config.OnConflict = tt.policy
+			if err := os.WriteFile(filepath.Join(s.Config.ProjectDir, "main.go"), []byte("package main"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			doc, err := s.BuildPlan()
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, f := range doc.Files {
+				if f.Path == "main.go" && f.Action != tt.want {
+					t.Errorf("expected main.go %s, got %+v", tt.want, f)
+				}
+			}
+		})
+	}
+}
+
+func TestRecorderNilSafe(t *testing.T) {
+	var r *Recorder
+	r.SetStep("x")
+	r.File(FileAction{Path: "a"})
+	r.Command(CommandAction{Command: "true"})
+}
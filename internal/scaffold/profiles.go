@@ -0,0 +1,98 @@
+package scaffold
+
+import "sort"
+
+// Built-in profile names.
+const (
+	ProfileLibrary = "library"
+	ProfileCLI     = "cli"
+	ProfileService = "service"
+	ProfileFull    = "full"
+)
+
+// Profile is a named set of capability overrides applied on top of the
+// default capabilities before any --<name>/--no-<name> flags.
+type Profile struct {
+	Name         string
+	Description  string
+	Capabilities map[string]bool
+	BuiltIn      bool
+}
+
+// Apply copies the profile's capability overrides into caps.
+func (p Profile) Apply(caps map[string]bool) {
+	for name, enabled := range p.Capabilities {
+		caps[name] = enabled
+	}
+}
+
+// BuiltinProfiles returns the profiles that ship with gsi, keyed by name.
+func BuiltinProfiles() map[string]Profile {
+	return map[string]Profile{
+		ProfileLibrary: {
+			Name:        ProfileLibrary,
+			Description: "Importable Go package: no binaries, containers or release pipeline",
+			Capabilities: map[string]bool{
+				CapBmad:       false,
+				CapUI:         false,
+				CapGoreleaser: false,
+				CapDocker:     false,
+				CapRelease:    false,
+			},
+			BuiltIn: true,
+		},
+		ProfileCLI: {
+			Name:        ProfileCLI,
+			Description: "Command-line tool released as binaries and Homebrew cask",
+			Capabilities: map[string]bool{
+				CapUI:         false,
+				CapGoreleaser: true,
+				CapDocker:     false,
+				CapRelease:    true,
+			},
+			BuiltIn: true,
+		},
+		ProfileService: {
+			Name:        ProfileService,
+			Description: "Long-running server shipped as a container image",
+			Capabilities: map[string]bool{
+				CapConfig:     true,
+				CapUI:         false,
+				CapGoreleaser: true,
+				CapDocker:     true,
+				CapRelease:    true,
+			},
+			BuiltIn: true,
+		},
+		ProfileFull: {
+			Name:         ProfileFull,
+			Description:  "Every capability, including the React UI",
+			Capabilities: allCapabilities(true),
+			BuiltIn:      true,
+		},
+	}
+}
+
+// CapabilityNames returns all known capability names in sorted order.
+func CapabilityNames() []string {
+	names := make([]string, 0, len(DefaultCapabilities()))
+	for name := range DefaultCapabilities() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IsCapability reports whether name is a known capability.
+func IsCapability(name string) bool {
+	_, ok := DefaultCapabilities()[name]
+	return ok
+}
+
+func allCapabilities(enabled bool) map[string]bool {
+	caps := DefaultCapabilities()
+	for name := range caps {
+		caps[name] = enabled
+	}
+	return caps
+}
@@ -0,0 +1,57 @@
+package scaffold
+
+import "testing"
+
+func TestBuiltinProfilesUseKnownCapabilities(t *testing.T) {
+	for name, p := range BuiltinProfiles() {
+		if p.Name != name {
+			t.Errorf("profile keyed %q has Name %q", name, p.Name)
+		}
+		if !p.BuiltIn {
+			t.Errorf("profile %q should be marked built-in", name)
+		}
+		for capName := range p.Capabilities {
+			if !IsCapability(capName) {
+				t.Errorf("profile %q references unknown capability %q", name, capName)
+			}
+		}
+	}
+}
+
+func TestProfileApplyLibrary(t *testing.T) {
+	caps := DefaultCapabilities()
+	BuiltinProfiles()[ProfileLibrary].Apply(caps)
+
+	for _, name := range []string{CapBmad, CapDocker, CapGoreleaser, CapRelease, CapUI} {
+		if caps[name] {
+			t.Errorf("expected %q disabled by library profile", name)
+		}
+	}
+	// Capabilities the profile doesn't mention keep their defaults
+	if !caps[CapDocs] || !caps[CapMakefile] {
+		t.Error("expected docs and makefile to keep their defaults")
+	}
+}
+
+func TestProfileApplyFull(t *testing.T) {
+	caps := DefaultCapabilities()
+	BuiltinProfiles()[ProfileFull].Apply(caps)
+
+	for name, enabled := range caps {
+		if !enabled {
+			t.Errorf("expected %q enabled by full profile", name)
+		}
+	}
+}
+
+func TestCapabilityNamesSorted(t *testing.T) {
+	names := CapabilityNames()
+	if len(names) != len(DefaultCapabilities()) {
+		t.Fatalf("expected %d names, got %d", len(DefaultCapabilities()), len(names))
+	}
+	for i := 1; i < len(names); i++ {
+		if names[i-1] > names[i] {
+			t.Errorf("names not sorted: %v", names)
+		}
+	}
+}
@@ -0,0 +1,307 @@
+package scaffold
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/joescharf/gsi/internal/templates"
+)
+
+// Step is a single unit of scaffold work. Each step declares the capability
+// that owns it, the tools it needs, the files it produces, and the steps that
+// must run before it, so Run can compute and display a plan up front.
+type Step struct {
+	Name        string   // unique identifier, e.g. "dockerfile"
+	Description string   // human label used in plan output and skip messages
+	Capability  string   // owning capability; empty for core steps that always run
+	CI          string   // CI provider the step belongs to; empty for steps that run under any
+	Tools       []string // commands that must be on PATH, checked just before running
+	Outputs     []string // files and directories produced, relative to ProjectDir
+	After       []string // names of steps that must run first
+	Docs        bool     // also runs in --only-docs mode
+	Online      bool     // needs the network and has no cached stand-in; skipped under --offline
+
+	// Inputs reports what the step's result depends on besides the steps
+	// it comes after, such as the settings its commands use or the content
+	// its template renders to. gsi resume runs a completed step again when
+	// these change. Nil means the step depends on nothing else.
+	Inputs func(*Scaffolder) any
+
+	// Run does the step's work with the given Scaffolder: the one that
+	// planned it, or a copy of it when steps run concurrently.
+	Run func(*Scaffolder) error
+}
+
+// PlannedStep is a Step together with the decision whether it will run.
+type PlannedStep struct {
+	Step
+	Skip   bool
+	Reason string // why the step is skipped, e.g. "--no-docker"
+}
+
+// templatePack returns the pack of files to generate: Scaffolder.Pack, or
+// the built-in pack when it is nil.
+func (s *Scaffolder) templatePack() (*templates.Pack, error) {
+	if s.Pack != nil {
+		return s.Pack, nil
+	}
+	return templates.BuiltinPack()
+}
+
+// Steps returns every registered scaffold step in declaration order: the
+// command steps that run external tools, and one step per file in the
+// template pack.
+func (s *Scaffolder) Steps() ([]Step, error) {
+	pack, err := s.templatePack()
+	if err != nil {
+		return nil, err
+	}
+	files, err := s.packSteps(pack)
+	if err != nil {
+		return nil, err
+	}
+
+	bmadTools := []string{"npx"}
+	if s.Config.Offline {
+		bmadTools = nil // copied from the artifact cache
+	}
+	steps := []Step{
+		{Name: "bmad", Description: "BMAD installation", Capability: CapBmad, Tools: bmadTools,
+			Outputs: []string{"_bmad"}, Inputs: gsiInputs, Run: (*Scaffolder).stepInstallBmad},
+		{Name: "go-mod-init", Description: "Go module initialization", Tools: []string{"go"},
+			Outputs: []string{"go.mod"}, Run: (*Scaffolder).stepGoModInit,
+			Inputs: func(s *Scaffolder) any { return []string{s.gsiVersion(), s.Config.GoModulePath} }},
+	}
+	steps = append(steps, files...)
+	steps = append(steps,
+		Step{Name: "go-mod-tidy", Description: "go mod tidy", Tools: []string{"go"},
+			After: append([]string{"go-mod-init"}, goSourceSteps(files)...), Outputs: []string{"go.sum"}, Inputs: gsiInputs, Run: (*Scaffolder).stepGoModTidy},
+		Step{Name: "docs", Description: "docs scaffolding", Capability: CapDocs, Tools: []string{"uv"}, Docs: true,
+			Outputs: []string{"docs"}, Run: (*Scaffolder).stepInitDocs,
+			Inputs: func(s *Scaffolder) any { return []string{s.gsiVersion(), docsProjectName(s.Config.ProjectName)} }},
+		Step{Name: "ui", Description: "UI initialization", Capability: CapUI, Tools: []string{"bun"},
+			Outputs: []string{"ui"}, Inputs: gsiInputs, Run: (*Scaffolder).stepInitUI},
+	)
+
+	// The manifest describes every file generated above, and git-init
+	// commits all of it, manifest included.
+	steps = append(steps, Step{Name: "manifest", Description: "gsi manifest", Docs: true,
+		After: stepNames(steps), Outputs: []string{ManifestName}, Run: (*Scaffolder).stepWriteManifest,
+		Inputs: func(s *Scaffolder) any { return s.checkpoint() }})
+	steps = append(steps, Step{Name: "git-init", Description: "git initialization", Capability: CapGit, Tools: []string{"git"},
+		After: stepNames(steps), Outputs: []string{".git"}, Inputs: gsiInputs, Run: (*Scaffolder).stepInitGit})
+	steps = append(steps, Step{Name: "github-pages", Description: "GitHub Pages configuration", Capability: CapDocs, CI: CIGitHub,
+		Online: true, After: []string{"git-init"}, Run: (*Scaffolder).stepConfigureGitHubPages,
+		Inputs: func(s *Scaffolder) any {
+			repo, _ := s.repository()
+			return []string{s.gsiVersion(), repo.Host, repo.Path()}
+		}})
+
+	return steps, nil
+}
+
+// gsiInputs is the Inputs of command steps that only depend on the gsi
+// version, which decides the commands they run.
+func gsiInputs(s *Scaffolder) any {
+	return s.gsiVersion()
+}
+
+// Plan orders the registered steps by their dependencies (keeping declaration
+// order where there is no constraint) and marks those that will be skipped.
+func (s *Scaffolder) Plan() ([]PlannedStep, error) {
+	steps, err := s.Steps()
+	if err != nil {
+		return nil, err
+	}
+	ordered, err := orderSteps(steps)
+	if err != nil {
+		return nil, err
+	}
+
+	ci := s.ciProvider()
+	plan := make([]PlannedStep, 0, len(ordered))
+	for _, step := range ordered {
+		p := PlannedStep{Step: step}
+		switch {
+		case s.Config.OnlyDocs && !step.Docs:
+			p.Skip, p.Reason = true, "--only-docs"
+		case step.Capability != "" && !s.Config.IsEnabled(step.Capability):
+			p.Skip, p.Reason = true, "--no-"+step.Capability
+		case step.CI != "" && step.CI != ci:
+			p.Skip, p.Reason = true, "--ci "+ci
+		case step.Online && s.Config.Offline:
+			p.Skip, p.Reason = true, "--offline"
+		}
+		plan = append(plan, p)
+	}
+	return plan, nil
+}
+
+// PrintPlan logs the plan, one line per step.
+func (s *Scaffolder) PrintPlan(plan []PlannedStep) {
+	s.Logger.Info("Plan:")
+	for i, p := range plan {
+		state := "run"
+		if p.Skip {
+			state = "skip (" + p.Reason + ")"
+		}
+		line := fmt.Sprintf("  %2d. %-18s %s", i+1, p.Name, state)
+		if len(p.Outputs) > 0 && !p.Skip {
+			line += "  → " + strings.Join(p.Outputs, ", ")
+		}
+		s.Logger.Plain(line)
+	}
+	s.Logger.Plain("")
+}
+
+// execute runs the planned steps, logging skipped ones. With Config.Jobs
+// above one, independent steps run concurrently (see executeConcurrent);
+// dry runs and --on-conflict=prompt always run one step at a time. Runs that
+// checkpoint record each step's outcome in the state file.
+func (s *Scaffolder) execute(plan []PlannedStep) error {
+	if s.Config.Jobs > 1 && !s.Config.DryRun && s.Config.OnConflict != ConflictPrompt {
+		return s.executeConcurrent(plan)
+	}
+	for _, p := range plan {
+		if !s.ready(p) {
+			continue
+		}
+		if err := s.interrupted(p); err != nil {
+			return err
+		}
+		s.Recorder.SetStep(p.Name)
+		err := p.Run(s)
+		s.saveCheckpoint(p.Step, err)
+		if err != nil {
+			s.pointToRunLog()
+			return err
+		}
+	}
+	return nil
+}
+
+// ready reports whether p should run, logging why not. A step whose tool is
+// missing turns its capability off.
+func (s *Scaffolder) ready(p PlannedStep) bool {
+	if p.Skip {
+		// --only-docs skips are implied by the mode; don't log each one
+		if p.Reason != "--only-docs" {
+			s.Logger.Info(fmt.Sprintf("Skipping %s (%s)", p.Description, p.Reason))
+		}
+		return false
+	}
+	// A soft dependency may have been disabled by an earlier step
+	if p.Capability != "" && !s.Config.IsEnabled(p.Capability) {
+		s.holdBack(p, p.Capability+" disabled")
+		s.Logger.Info(fmt.Sprintf("Skipping %s (%s disabled)", p.Description, p.Capability))
+		return false
+	}
+	if tool := missingTool(p.Tools); tool != "" {
+		s.holdBack(p, tool+" not found")
+		s.Logger.Warning(fmt.Sprintf("Skipping %s (%s not found)", p.Description, tool))
+		if p.Capability != "" {
+			s.Config.Disable(p.Capability)
+		}
+		return false
+	}
+	return true
+}
+
+// holdBack records why execute did not run a planned step, for BuildPlan.
+func (s *Scaffolder) holdBack(p PlannedStep, reason string) {
+	if s.heldBack == nil {
+		s.heldBack = map[string]string{}
+	}
+	s.heldBack[p.Name] = reason
+}
+
+// interrupted returns an error, recorded as p's outcome, once the run has
+// been cancelled. It stops the run between steps too, not only while a
+// command runs.
+func (s *Scaffolder) interrupted(p PlannedStep) error {
+	if ctx := s.Executor.Context; ctx == nil || ctx.Err() == nil {
+		return nil
+	}
+	s.holdBack(p, "interrupted")
+	err := fmt.Errorf("%s: interrupted", p.Description)
+	s.saveCheckpoint(p.Step, err)
+	return err
+}
+
+// pointToRunLog tells where to find the output of the command that failed.
+func (s *Scaffolder) pointToRunLog() {
+	if s.runLog != "" {
+		s.Logger.Info("Command output is in " + s.runLog)
+	}
+}
+
+// missingTool returns the first of tools not found on PATH, or "".
+func missingTool(tools []string) string {
+	for _, tool := range tools {
+		if !CheckCommand(tool) {
+			return tool
+		}
+	}
+	return ""
+}
+
+// orderSteps topologically sorts steps by their After dependencies. Among
+// steps whose dependencies are satisfied, declaration order wins.
+func orderSteps(steps []Step) ([]Step, error) {
+	index := make(map[string]int, len(steps))
+	for i, step := range steps {
+		if _, dup := index[step.Name]; dup {
+			return nil, fmt.Errorf("duplicate step %q", step.Name)
+		}
+		index[step.Name] = i
+	}
+	for _, step := range steps {
+		for _, dep := range step.After {
+			if _, ok := index[dep]; !ok {
+				return nil, fmt.Errorf("step %q depends on unknown step %q", step.Name, dep)
+			}
+		}
+	}
+
+	done := make(map[string]bool, len(steps))
+	ordered := make([]Step, 0, len(steps))
+	for len(ordered) < len(steps) {
+		progressed := false
+		for _, step := range steps {
+			if done[step.Name] || !depsDone(step, done) {
+				continue
+			}
+			done[step.Name] = true
+			ordered = append(ordered, step)
+			progressed = true
+			break
+		}
+		if !progressed {
+			var stuck []string
+			for _, step := range steps {
+				if !done[step.Name] {
+					stuck = append(stuck, step.Name)
+				}
+			}
+			return nil, fmt.Errorf("dependency cycle among steps: %s", strings.Join(stuck, ", "))
+		}
+	}
+	return ordered, nil
+}
+
+func depsDone(step Step, done map[string]bool) bool {
+	for _, dep := range step.After {
+		if !done[dep] {
+			return false
+		}
+	}
+	return true
+}
+
+func stepNames(steps []Step) []string {
+	names := make([]string, len(steps))
+	for i, step := range steps {
+		names[i] = step.Name
+	}
+	return names
+}
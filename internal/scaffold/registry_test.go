@@ -0,0 +1,183 @@
+package scaffold
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestOrderStepsRespectsDependencies(t *testing.T) {
+	steps := []Step{
+		{Name: "c", After: []string{"b"}},
+		{Name: "a"},
+		{Name: "b", After: []string{"a"}},
+		{Name: "d"},
+	}
+	ordered, err := orderSteps(steps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := strings.Join(stepNames(ordered), ",")
+	if got != "a,b,c,d" {
+		t.Errorf("order = %s, want a,b,c,d", got)
+	}
+}
+
+func TestOrderStepsErrors(t *testing.T) {
+	if _, err := orderSteps([]Step{{Name: "a", After: []string{"missing"}}}); err == nil {
+		t.Error("expected error for unknown dependency")
+	}
+	if _, err := orderSteps([]Step{{Name: "a", After: []string{"b"}}, {Name: "b", After: []string{"a"}}}); err == nil {
+		t.Error("expected error for dependency cycle")
+	}
+	if _, err := orderSteps([]Step{{Name: "a"}, {Name: "a"}}); err == nil {
+		t.Error("expected error for duplicate step")
+	}
+}
+
+func TestRegistryIsValid(t *testing.T) {
+	s, _, _ := testScaffolder(t, false)
+	plan, err := s.Plan()
+	if err != nil {
+		t.Fatalf("Plan failed: %v", err)
+	}
+	steps, err := s.Steps()
+	if err != nil {
+		t.Fatalf("Steps failed: %v", err)
+	}
+	if len(plan) != len(steps) {
+		t.Errorf("plan has %d steps, registry has %d", len(plan), len(steps))
+	}
+	for _, p := range plan {
+		if p.Run == nil {
+			t.Errorf("step %q has no Run function", p.Name)
+		}
+		if p.Capability != "" && !IsCapability(p.Capability) {
+			t.Errorf("step %q owned by unknown capability %q", p.Name, p.Capability)
+		}
+	}
+	// git-init commits everything, so it must come after all file generators
+	last := plan[len(plan)-2].Name
+	if last != "git-init" {
+		t.Errorf("expected git-init to be second to last, got %q", last)
+	}
+}
+
+func TestPlanSkipsDisabledCapabilities(t *testing.T) {
+	s, _, _ := testScaffolder(t, false)
+	s.Config.Capabilities[CapDocker] = false
+
+	plan, err := s.Plan()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range plan {
+		switch {
+		case p.Capability == CapDocker:
+			if !p.Skip || p.Reason != "--no-docker" {
+				t.Errorf("expected %q skipped with --no-docker, got skip=%v reason=%q", p.Name, p.Skip, p.Reason)
+			}
+		case p.Capability == "" && p.CI == "":
+			if p.Skip {
+				t.Errorf("core step %q should not be skipped", p.Name)
+			}
+		}
+	}
+}
+
+func TestPlanSkipsOtherCIProviders(t *testing.T) {
+	for _, ci := range []string{CIGitHub, CIGitLab, CIGitea, CINone} {
+		s, _, _ := testScaffolder(t, false)
+		s.Config.CI = ci
+
+		plan, err := s.Plan()
+		if err != nil {
+			t.Fatal(err)
+		}
+		ran := 0
+		for _, p := range plan {
+			if p.CI == "" {
+				continue
+			}
+			if p.CI == ci {
+				ran++
+				if p.Skip {
+					t.Errorf("--ci %s: step %q should run, skipped with %q", ci, p.Name, p.Reason)
+				}
+			} else if !p.Skip || p.Reason != "--ci "+ci {
+				t.Errorf("--ci %s: expected %q skipped with --ci %s, got skip=%v reason=%q", ci, p.Name, ci, p.Skip, p.Reason)
+			}
+		}
+		if ci != CINone && ran == 0 {
+			t.Errorf("--ci %s: no steps belong to it", ci)
+		}
+	}
+}
+
+func TestPlanOnlyDocs(t *testing.T) {
+	s, _, _ := testScaffolder(t, false)
+	s.Config.OnlyDocs = true
+
+	plan, err := s.Plan()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var running []string
+	for _, p := range plan {
+		if p.Skip {
+			continue
+		}
+		running = append(running, p.Name)
+		if p.Name != "manifest" && p.Capability != CapDocs {
+			t.Errorf("step %q runs in --only-docs mode", p.Name)
+		}
+	}
+	if len(running) < 3 || running[0] != "docs" || running[len(running)-1] != "manifest" {
+		t.Errorf("expected the docs step, its pack files, then the manifest; got %v", running)
+	}
+	if !slices.Contains(running, "mkdocs-config") {
+		t.Errorf("expected docs pack files to run, got %v", running)
+	}
+}
+
+func TestExecuteSkipsMissingTool(t *testing.T) {
+	s, _, stderr := testScaffolder(t, false)
+	ran := false
+	plan := []PlannedStep{{Step: Step{
+		Name:        "needs-tool",
+		Description: "tool step",
+		Capability:  CapDocs,
+		Tools:       []string{"nonexistent_command_xyz_12345"},
+		Run:         func(*Scaffolder) error { ran = true; return nil },
+	}}}
+
+	if err := s.execute(plan); err != nil {
+		t.Fatal(err)
+	}
+	if ran {
+		t.Error("step should not run when its tool is missing")
+	}
+	if !strings.Contains(stderr.String(), "nonexistent_command_xyz_12345 not found") {
+		t.Errorf("expected missing tool warning, got %q", stderr.String())
+	}
+	if s.Config.IsEnabled(CapDocs) {
+		t.Error("expected owning capability to be disabled")
+	}
+}
+
+func TestPrintPlan(t *testing.T) {
+	s, stdout, _ := testScaffolder(t, false)
+	s.Config.Capabilities[CapMockery] = false
+	plan, err := s.Plan()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.PrintPlan(plan)
+
+	out := stdout.String()
+	for _, want := range []string{"Plan:", "goreleaser", ".goreleaser.yml", "skip (--no-mockery)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("plan output missing %q:\n%s", want, out)
+		}
+	}
+}
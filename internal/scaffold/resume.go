@@ -0,0 +1,82 @@
+package scaffold
+
+import (
+	"fmt"
+	"os"
+)
+
+// Resume continues the scaffold checkpointed in Config.ProjectDir by a run
+// that failed. Steps that completed are skipped as long as their inputs are
+// unchanged; the failed step and those that did not complete run. After a
+// run that was rolled back rather than kept (see Config.KeepFailed), no step
+// counts as completed and the scaffold runs again from the start with the
+// recorded settings. Settings already in Config replace the recorded ones,
+// and a step whose inputs changed runs again along with every step that
+// depends on it.
+func (s *Scaffolder) Resume() (err error) {
+	cfg := &s.Config
+	if cfg.ProjectDir == "" {
+		dir, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("getting current directory: %w", err)
+		}
+		cfg.ProjectDir = dir
+	}
+	s.Executor.Dir = cfg.ProjectDir
+
+	prev, err := ReadState(s.FS, cfg.ProjectDir)
+	if err != nil {
+		return err
+	}
+	if prev == nil {
+		return fmt.Errorf("nothing to resume in %s: no %s", cfg.ProjectDir, StateName)
+	}
+	prev.applyTo(cfg)
+	if err := s.validateIdentifiers(); err != nil {
+		return err
+	}
+	if err := s.resolveProjectDefaults(); err != nil {
+		return err
+	}
+	if err := s.loadPacks(); err != nil {
+		return err
+	}
+
+	s.Logger.Plain("")
+	if prev.Failed != "" {
+		s.Logger.Info(fmt.Sprintf("Resuming a scaffold that failed at %s (%d steps completed)", prev.Failed, len(prev.Steps)))
+	} else {
+		s.Logger.Info(fmt.Sprintf("Resuming a scaffold that stopped after %d steps", len(prev.Steps)))
+	}
+	s.printConfiguration()
+	if err := s.checkEnvironment(); err != nil {
+		return err
+	}
+	s.loadManifest()
+
+	plan, err := s.Plan()
+	if err != nil {
+		return err
+	}
+	s.state = s.checkpoint()
+	s.resumeFrom(prev, plan)
+	if cfg.DryRun {
+		s.state = nil
+	}
+	if cfg.DryRun || cfg.Verbose {
+		s.PrintPlan(plan)
+	}
+
+	defer s.endJournal(&err)
+	if err := s.beginJournal(); err != nil {
+		return err
+	}
+	defer s.openRunLog(cfg.ProjectName)()
+	if err := s.execute(plan); err != nil {
+		return err
+	}
+	s.clearCheckpoint()
+
+	s.stepPrintSummary()
+	return nil
+}
@@ -0,0 +1,290 @@
+package scaffold
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// fakeGo puts a go on PATH that only writes go.mod for go mod init, and
+// fails go mod tidy while the returned file exists.
+func fakeGo(t *testing.T) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake go is a shell script")
+	}
+	bin := t.TempDir()
+	fail := filepath.Join(bin, "fail")
+	writeTestFile(t, filepath.Join(bin, "go"), `#!/bin/sh
+case "$1 $2" in
+"mod init") echo "module $3" > go.mod ;;
+"mod tidy") [ -f "`+fail+`" ] && exit 1 ;;
+esac
+exit 0
+`)
+	if err := os.Chmod(filepath.Join(bin, "go"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin)
+	writeTestFile(t, fail, "")
+	return fail
+}
+
+// failedRun scaffolds a new project whose go mod tidy fails, returning its
+// directory. keep sets --keep-on-failure.
+func failedRun(t *testing.T, keep bool) (string, string) {
+	t.Helper()
+	return failedRunIn(t, keep, false)
+}
+
+// failedRunIn is failedRun, in an existing empty directory when existing is
+// set.
+func failedRunIn(t *testing.T, keep, existing bool) (string, string) {
+	t.Helper()
+	fail := fakeGo(t)
+	s, _, _ := testScaffolder(t, false)
+	dir := filepath.Join(s.Config.ProjectDir, "app")
+	if existing {
+		if err := os.Mkdir(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s.Config.ProjectName = dir
+	s.Config.GoModulePath = ""
+	s.Config.ModulePrefix = "github.com/example"
+	s.Config.GoVersion = "1.24"
+	s.Config.Capabilities = map[string]bool{CapDocker: true}
+	s.Config.KeepFailed = keep
+	if err := s.Run(); err == nil {
+		t.Fatal("expected the run to fail")
+	}
+	return dir, fail
+}
+
+// resumer returns a test scaffolder that resumes the project in dir.
+func resumer(t *testing.T, dir string) (*Scaffolder, *strings.Builder) {
+	t.Helper()
+	s, stdout, _ := testScaffolder(t, false)
+	s.Config = Config{ProjectDir: dir, Verbose: true}
+	return s, stdout
+}
+
+func TestRunCheckpoints(t *testing.T) {
+	dir, _ := failedRun(t, true)
+	st, err := ReadState(OSFS{}, dir)
+	if err != nil || st == nil {
+		t.Fatalf("expected a checkpoint, got %v, %v", st, err)
+	}
+	if st.Failed != "go-mod-tidy" {
+		t.Errorf("failed step = %q, want go-mod-tidy", st.Failed)
+	}
+	var names []string
+	for _, step := range st.Steps {
+		names = append(names, step.Name)
+		if !strings.HasPrefix(step.Inputs, "sha256:") {
+			t.Errorf("%s: expected an inputs hash, got %q", step.Name, step.Inputs)
+		}
+	}
+	if len(names) == 0 || names[0] != "go-mod-init" || strings.Contains(strings.Join(names, " "), "go-mod-tidy") {
+		t.Errorf("completed steps = %v, want go-mod-init first and no go-mod-tidy", names)
+	}
+	if st.Project.Name != "app" || st.Project.ModulePath != "github.com/example/app" || !st.Capabilities[CapDocker] {
+		t.Errorf("expected the settings to be recorded, got %+v %v", st.Project, st.Capabilities)
+	}
+	if len(st.Files) == 0 {
+		t.Error("expected the completed steps' files to be recorded")
+	}
+}
+
+func TestResume(t *testing.T) {
+	dir, fail := failedRun(t, true)
+	if err := os.Remove(fail); err != nil {
+		t.Fatal(err)
+	}
+
+	s, stdout := resumer(t, dir)
+	if err := s.Resume(); err != nil {
+		t.Fatalf("Resume failed: %v", err)
+	}
+	var commands []string
+	for _, c := range s.Recorder.Commands {
+		commands = append(commands, c.Command)
+	}
+	if got := strings.Join(commands, "\n"); got != "go mod tidy" {
+		t.Errorf("expected only go mod tidy to run, got:\n%s", got)
+	}
+	if !strings.Contains(stdout.String(), "Skipping Go module initialization (completed)") {
+		t.Errorf("expected completed steps to be skipped, got:\n%s", stdout)
+	}
+	if _, err := os.Stat(filepath.Join(dir, StateName)); !os.IsNotExist(err) {
+		t.Errorf("expected the checkpoint to be removed, got %v", err)
+	}
+	// The manifest covers the files written before the failure
+	m, err := ReadManifest(OSFS{}, dir)
+	if err != nil || m == nil {
+		t.Fatalf("expected a manifest, got %v", err)
+	}
+	if _, ok := m.File("main.go"); !ok {
+		t.Errorf("expected main.go in the manifest, got %+v", m.Files)
+	}
+	if m.Project.Name != "app" || !m.Capabilities[CapDocker] {
+		t.Errorf("expected the recorded settings in the manifest, got %+v %v", m.Project, m.Capabilities)
+	}
+}
+
+func TestResumeChangedInputs(t *testing.T) {
+	dir, fail := failedRun(t, true)
+	if err := os.Remove(fail); err != nil {
+		t.Fatal(err)
+	}
+
+	s, stdout := resumer(t, dir)
+	s.Config.Description = "Something else"
+	if err := s.Resume(); err != nil {
+		t.Fatalf("Resume failed: %v", err)
+	}
+	// Only the root command's template reads the description
+	if !strings.Contains(stdout.String(), "Inputs of root command changed") {
+		t.Errorf("expected the root command's checkpoint to be invalidated, got:\n%s", stdout)
+	}
+	for _, step := range []string{"Go module initialization", "Dockerfile", "main.go"} {
+		if !strings.Contains(stdout.String(), "Skipping "+step+" (completed)") {
+			t.Errorf("expected %s to stay completed, got:\n%s", step, stdout)
+		}
+	}
+	m, _ := ReadManifest(OSFS{}, dir)
+	if m == nil || m.Project.Description != "Something else" {
+		t.Errorf("expected the new description in the manifest, got %+v", m)
+	}
+}
+
+func TestResumeWithoutCapability(t *testing.T) {
+	dir, fail := failedRun(t, true)
+	if err := os.Remove(fail); err != nil {
+		t.Fatal(err)
+	}
+
+	s, stdout := resumer(t, dir)
+	s.Config.Capabilities = map[string]bool{CapDocker: false}
+	if err := s.Resume(); err != nil {
+		t.Fatalf("Resume failed: %v", err)
+	}
+	if strings.Contains(stdout.String(), "Inputs of") {
+		t.Errorf("expected no template to depend on docker, got:\n%s", stdout)
+	}
+	if !strings.Contains(stdout.String(), "Skipping root command (completed)") {
+		t.Errorf("expected the completed steps to be skipped, got:\n%s", stdout)
+	}
+	m, _ := ReadManifest(OSFS{}, dir)
+	if m == nil || m.Capabilities[CapDocker] {
+		t.Errorf("expected docker off in the manifest, got %+v", m)
+	}
+}
+
+func TestResumeFailureKeepsCheckpoint(t *testing.T) {
+	dir, _ := failedRun(t, true)
+	before, err := os.ReadFile(filepath.Join(dir, StateName))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Still failing: the resume is rolled back to where it started
+	s, _ := resumer(t, dir)
+	if err := s.Resume(); err == nil {
+		t.Fatal("expected the resume to fail")
+	}
+	after, err := os.ReadFile(filepath.Join(dir, StateName))
+	if err != nil || string(after) != string(before) {
+		t.Errorf("expected the checkpoint to be restored, got %v:\n%s", err, after)
+	}
+}
+
+func TestResumeAfterRollback(t *testing.T) {
+	dir, fail := failedRunIn(t, false, true)
+	st, err := ReadState(OSFS{}, dir)
+	if err != nil || st == nil {
+		t.Fatalf("expected the checkpoint to survive the rollback, got %v, %v", st, err)
+	}
+	if st.Failed != "go-mod-tidy" || len(st.Steps) != 0 || len(st.Files) != 0 {
+		t.Errorf("expected no completed steps after the rollback, got %+v", st)
+	}
+	if err := os.Remove(fail); err != nil {
+		t.Fatal(err)
+	}
+
+	s, _ := resumer(t, dir)
+	if err := s.Resume(); err != nil {
+		t.Fatalf("Resume failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "main.go")); err != nil {
+		t.Errorf("expected the resume to scaffold the project again, got %v", err)
+	}
+}
+
+func TestRollbackRemovesNewDirectory(t *testing.T) {
+	dir, _ := failedRun(t, false)
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("expected %s not to exist after the rollback, got %v", dir, err)
+	}
+}
+
+func TestClearCheckpointRemovesEmptyDirectory(t *testing.T) {
+	s, _, _ := testScaffolder(t, false)
+	gsiDir := filepath.Join(s.Config.ProjectDir, ".gsi")
+	s.state = s.checkpoint()
+	s.writeCheckpoint()
+	s.clearCheckpoint()
+	if _, err := os.Stat(gsiDir); !os.IsNotExist(err) {
+		t.Errorf("expected the empty .gsi to be removed, got %v", err)
+	}
+
+	if err := os.MkdirAll(filepath.Join(gsiDir, "baseline"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, filepath.Join(gsiDir, "baseline", "main.go"), "package main\n")
+	s.state = s.checkpoint()
+	s.writeCheckpoint()
+	s.clearCheckpoint()
+	if _, err := os.Stat(filepath.Join(gsiDir, "baseline", "main.go")); err != nil {
+		t.Errorf("expected the rest of .gsi to be kept, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(s.Config.ProjectDir, StateName)); !os.IsNotExist(err) {
+		t.Errorf("expected the checkpoint to be removed, got %v", err)
+	}
+}
+
+func TestResumeWithoutCheckpoint(t *testing.T) {
+	s, _ := resumer(t, t.TempDir())
+	if err := s.Resume(); err == nil || !strings.Contains(err.Error(), "nothing to resume") {
+		t.Errorf("expected nothing to resume, got %v", err)
+	}
+}
+
+func TestStateApplyTo(t *testing.T) {
+	st := &State{
+		Project: ManifestProject{
+			Name: "app", ModulePath: "github.com/acme/app", Author: "A", License: "MIT", CI: CIGitHub,
+		},
+		Capabilities: map[string]bool{CapDocker: true, CapUI: true},
+		Packs:        []ManifestPack{{Source: "https://example.com/pack.git", Ref: "v1"}},
+	}
+	cfg := Config{Author: "B", Capabilities: map[string]bool{CapUI: false}}
+	st.applyTo(&cfg)
+	if cfg.ProjectName != "app" || cfg.GoModulePath != "github.com/acme/app" || cfg.License != "MIT" {
+		t.Errorf("expected the recorded project, got %+v", cfg)
+	}
+	if cfg.Author != "B" {
+		t.Errorf("expected the given author to win, got %q", cfg.Author)
+	}
+	if !cfg.Capabilities[CapDocker] || cfg.Capabilities[CapUI] {
+		t.Errorf("expected docker on and ui off, got %v", cfg.Capabilities)
+	}
+	if st.Capabilities[CapUI] != true {
+		t.Error("applyTo modified the recorded capabilities")
+	}
+	if len(cfg.Packs) != 1 || cfg.Packs[0] != "https://example.com/pack.git@v1" {
+		t.Errorf("expected the recorded pack, got %v", cfg.Packs)
+	}
+}
@@ -2,12 +2,16 @@ package scaffold
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"regexp"
 	"sort"
+	"strings"
+	"time"
 
 	"github.com/joescharf/gsi/internal/logger"
+	"github.com/joescharf/gsi/internal/templates"
 )
 
 // Scaffolder holds all state needed to run the scaffold steps.
@@ -15,27 +19,67 @@ type Scaffolder struct {
 	Config   Config
 	Logger   *logger.Logger
 	Executor *Executor
+	FS       FS              // target of all file writes; a MemFS overlay in dry-run
+	Recorder *Recorder       // collects file and command actions for plans and the manifest
+	In       io.Reader       // answers for --on-conflict=prompt (default os.Stdin)
+	Pack     *templates.Pack // files to generate; nil means the built-in pack
+
+	fresh    *pathSet            // files created this run by bun
+	manifest *Manifest           // manifest from an earlier run, loaded by loadManifest
+	packs    []ManifestPack      // packs resolved by loadPacks, recorded in the manifest
+	renderer *templates.Renderer // override and pack directories templates are read from
+
+	goModCache string   // module cache go mod tidy downloads into while warming the artifact cache
+	runLog     string   // path of the command log opened by openRunLog
+	journal    *journal // project state before the run, opened by beginJournal
+	state      *State   // checkpoint of this run; nil when it does not checkpoint
+
+	heldBack map[string]string // planned steps execute did not run, with why
 }
 
 // NewScaffolder creates a Scaffolder from the given Config.
 func NewScaffolder(cfg Config) *Scaffolder {
 	log := logger.New(cfg.Verbose)
+	var fsys FS = OSFS{}
+	if cfg.DryRun {
+		fsys = NewMemFS(OSFS{})
+	}
+	rec := &Recorder{}
 	return &Scaffolder{
-		Config: cfg,
-		Logger: log,
+		Config:   cfg,
+		Logger:   log,
+		FS:       fsys,
+		Recorder: rec,
+		fresh:    &pathSet{},
+		renderer: &templates.Renderer{Overrides: cfg.TemplateDirs},
 		Executor: &Executor{
-			DryRun: cfg.DryRun,
-			Logger: log,
-			Dir:    cfg.ProjectDir,
+			DryRun:   cfg.DryRun,
+			Logger:   log,
+			Dir:      cfg.ProjectDir,
+			Recorder: rec,
 		},
 	}
 }
 
+// DefaultModulePath joins a module prefix and project name. Without a prefix
+// the module path is the bare project name, which names no repository.
+func DefaultModulePath(prefix, projectName string) string {
+	if prefix == "" {
+		return projectName
+	}
+	return strings.TrimSuffix(prefix, "/") + "/" + projectName
+}
+
 var validProjectName = regexp.MustCompile(`^[a-zA-Z0-9_/.\-]+$`)
 
-// Run is the main orchestrator that sequences all scaffold steps.
-func (s *Scaffolder) Run() error {
+// Run is the main orchestrator that sequences all scaffold steps. When it
+// fails, the project directory is rolled back to its state before the run
+// unless Config.KeepFailed is set. In an existing directory the checkpoint
+// survives the rollback, so gsi resume can run the scaffold again with the
+// same settings.
+func (s *Scaffolder) Run() (err error) {
 	cfg := &s.Config
+	defer s.endJournal(&err)
 
 	// Resolve project name and directory
 	if cfg.ProjectName == "." || cfg.ProjectName == "./" {
@@ -45,6 +89,12 @@ func (s *Scaffolder) Run() error {
 		}
 		cfg.ProjectDir = dir
 		cfg.ProjectName = filepath.Base(dir)
+		if err := s.validateIdentifiers(); err != nil {
+			return err
+		}
+		if err := s.beginJournal(); err != nil {
+			return err
+		}
 		s.Logger.Info("Initializing in current directory")
 		s.Logger.VerboseMsg("Project directory: " + cfg.ProjectDir)
 	} else {
@@ -62,6 +112,12 @@ func (s *Scaffolder) Run() error {
 			cfg.ProjectDir = filepath.Join(cwd, cfg.ProjectName)
 		}
 		cfg.ProjectName = filepath.Base(cfg.ProjectDir)
+		if err := s.validateIdentifiers(); err != nil {
+			return err
+		}
+		if err := s.beginJournal(); err != nil {
+			return err
+		}
 
 		// Create or reuse directory
 		info, err := os.Stat(cfg.ProjectDir)
@@ -87,16 +143,80 @@ func (s *Scaffolder) Run() error {
 
 	// Set defaults for module path
 	if cfg.GoModulePath == "" {
-		cfg.GoModulePath = "github.com/joescharf/" + cfg.ProjectName
+		cfg.GoModulePath = DefaultModulePath(cfg.ModulePrefix, cfg.ProjectName)
 	}
 
-	// Display configuration
+	if err := s.resolveProjectDefaults(); err != nil {
+		return err
+	}
+	if err := s.loadPacks(); err != nil {
+		return err
+	}
+
+	s.printConfiguration()
+	if err := s.checkEnvironment(); err != nil {
+		return err
+	}
+
+	// Check existing state
+	CheckExistingState(cfg.ProjectDir, s.Logger)
+	s.loadManifest()
+	if st, _ := ReadState(s.FS, cfg.ProjectDir); st != nil {
+		s.Logger.Warning(fmt.Sprintf("An earlier scaffold failed at %s; starting over (use 'gsi resume' to continue it instead)", st.Failed))
+	}
+
+	s.Logger.Plain("")
+	s.Logger.Info("Starting project initialization...")
+	s.Logger.Plain("")
+
+	plan, err := s.Plan()
+	if err != nil {
+		return err
+	}
+	if cfg.DryRun || cfg.Verbose {
+		s.PrintPlan(plan)
+	}
+
+	if !cfg.DryRun {
+		s.state = s.checkpoint()
+	}
+	defer s.openRunLog(cfg.ProjectName)()
+	if err := s.execute(plan); err != nil {
+		return err
+	}
+	s.clearCheckpoint()
+
+	s.stepPrintSummary()
+	return nil
+}
+
+// printConfiguration shows the resolved settings and capabilities.
+func (s *Scaffolder) printConfiguration() {
+	cfg := &s.Config
 	s.Logger.Plain("")
 	s.Logger.Info("Configuration:")
 	s.Logger.Plain("  Project Name:  " + cfg.ProjectName)
 	s.Logger.Plain("  Project Dir:   " + cfg.ProjectDir)
 	s.Logger.Plain("  Module Path:   " + cfg.GoModulePath)
+	if repo, err := s.repository(); err == nil && repo.URL != "" {
+		s.Logger.Plain("  Repository:    " + repo.URL)
+	}
 	s.Logger.Plain("  Author:        " + cfg.Author)
+	if cfg.Description != "" {
+		s.Logger.Plain("  Description:   " + cfg.Description)
+	}
+	s.Logger.Plain("  License:       " + cfg.License)
+	s.Logger.Plain("  CI:            " + cfg.CI)
+	if cfg.Profile != "" {
+		s.Logger.Plain("  Profile:       " + cfg.Profile)
+	}
+	if cfg.OnConflict != "" && cfg.OnConflict != ConflictSkip {
+		s.Logger.Plain("  On Conflict:   " + string(cfg.OnConflict))
+	}
+	s.printPacks()
+	if cfg.Offline {
+		s.Logger.Plain("  Offline:       " + cfg.ArtifactDir)
+	}
 	if cfg.DryRun {
 		s.Logger.Plain("  \033[1;33mMode:          DRY-RUN\033[0m")
 	}
@@ -121,6 +241,12 @@ func (s *Scaffolder) Run() error {
 		s.Logger.Plain("  only-docs:     ON")
 	}
 	s.Logger.Plain("")
+}
+
+// checkEnvironment validates the settings and the tools they need, turning
+// off capabilities whose optional tools are missing.
+func (s *Scaffolder) checkEnvironment() error {
+	cfg := &s.Config
 
 	// Validate mutually exclusive flags
 	if cfg.OnlyDocs && !cfg.IsEnabled(CapDocs) {
@@ -148,72 +274,87 @@ func (s *Scaffolder) Run() error {
 			cfg.Disable(CapDocs)
 		}
 	}
+	return nil
+}
 
-	// Check existing state
-	CheckExistingState(cfg.ProjectDir, s.Logger)
-
-	s.Logger.Plain("")
-	s.Logger.Info("Starting project initialization...")
-	s.Logger.Plain("")
-
-	// Run steps — respect --only-docs guard
-	if !cfg.OnlyDocs {
-		steps := []func() error{
-			s.stepInstallBmad,
-			s.stepInstallCobraCli,
-			s.stepGoModInit,
-			s.stepCobraInit,
-			s.stepGenerateMainGo,
-			s.stepGenerateRootCmd,
-			s.stepGenerateVersionCmd,
-			s.stepGenerateServeCmd,
-			s.stepGenerateConfigCmd,
-			s.stepGenerateConfigPkg,
-			s.stepGenerateConfigInit,
-			s.stepGenerateMockeryConfig,
-			s.stepGenerateEditorConfig,
-			s.stepGenerateUIPlaceholder,
-			s.stepGenerateEmbedGo,
-			s.stepGoModTidy,
-			s.stepGenerateMakefile,
-			s.stepGenerateGolangciLintConfig,
-			s.stepGenerateGoreleaser,
-			s.stepGenerateDockerfile,
-			s.stepGenerateDockerignore,
-			s.stepGenerateReleaseWorkflow,
-			s.stepGenerateCIWorkflow,
-			s.stepGenerateDocsWorkflow,
-			s.stepGeneratePycodesignConfig,
-		}
-		for _, step := range steps {
-			if err := step(); err != nil {
-				return err
-			}
-		}
+// beginJournal records the state of Config.ProjectDir so that endJournal
+// can roll back a failed run. It does nothing in dry-run mode.
+func (s *Scaffolder) beginJournal() error {
+	if s.Config.DryRun {
+		return nil
 	}
-
-	// Docs (runs in both normal and --only-docs mode)
-	if err := s.stepInitDocs(); err != nil {
+	j, err := beginJournal(s.Config.ProjectDir)
+	if err != nil {
 		return err
 	}
+	s.journal = j
+	return nil
+}
 
-	if !cfg.OnlyDocs {
-		// UI init
-		if err := s.stepInitUI(); err != nil {
-			return err
-		}
-
-		// Git init
-		if err := s.stepInitGit(); err != nil {
-			return err
-		}
-
-		// Configure GitHub Pages
-		if err := s.stepConfigureGitHubPages(); err != nil {
-			return err
-		}
+// endJournal keeps the run's changes when *err is nil and otherwise rolls
+// the project directory back, unless Config.KeepFailed is set. The
+// checkpoint of a rolled back run is written again afterwards (see
+// keepCheckpoint), except in a directory the run created, which is left
+// removed. A failed rollback is added to *err.
+func (s *Scaffolder) endJournal(err *error) {
+	j := s.journal
+	if j == nil {
+		return
+	}
+	s.journal = nil
+	if *err == nil {
+		j.commit()
+		return
+	}
+	if s.Config.KeepFailed {
+		j.commit()
+		s.Logger.Warning("Keeping the changes made before the failure (--keep-on-failure) in " + j.root)
+		return
 	}
+	s.Logger.Info("Rolling back " + j.root + " (use --keep-on-failure to keep the completed steps)")
+	if rerr := j.rollback(); rerr != nil {
+		s.Logger.Error("Rollback failed: " + rerr.Error())
+		*err = fmt.Errorf("%w (rollback failed: %v)", *err, rerr)
+		return
+	}
+	if j.created {
+		s.Logger.Success("Removed " + j.root)
+	} else {
+		s.Logger.Success("Restored " + j.root + " to its state before the run")
+	}
+	switch {
+	case s.state == nil:
+	case j.created:
+		// Nothing had completed before the run; run it again to retry
+		s.Logger.Info("Run the same command again to retry")
+	default:
+		s.keepCheckpoint()
+		s.Logger.Info("Kept " + filepath.Join(j.root, StateName) + "; run 'gsi resume' to try again with the same settings")
+	}
+}
 
-	s.stepPrintSummary()
-	return nil
+// openRunLog starts a command log for this run in Config.LogDir, named after
+// the time and name, and returns a function that closes it. Without a
+// LogDir, in dry-run mode, or when the log cannot be created, commands are
+// not logged.
+func (s *Scaffolder) openRunLog(name string) func() {
+	if s.Config.LogDir == "" || s.Config.DryRun {
+		return func() {}
+	}
+	if err := os.MkdirAll(s.Config.LogDir, 0o755); err != nil {
+		s.Logger.Warning("Could not create the command log: " + err.Error())
+		return func() {}
+	}
+	path := filepath.Join(s.Config.LogDir, time.Now().Format("20060102-150405")+"-"+name+".log")
+	f, err := os.Create(path)
+	if err != nil {
+		s.Logger.Warning("Could not create the command log: " + err.Error())
+		return func() {}
+	}
+	s.Logger.VerboseMsg("Command log: " + path)
+	s.Executor.Log, s.runLog = f, path
+	return func() {
+		s.Executor.Log = nil
+		_ = f.Close()
+	}
 }
@@ -0,0 +1,246 @@
+package scaffold
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// StateName is the checkpoint file, relative to the project directory, in
+// which a scaffold records its progress so gsi resume can continue it after
+// a failure. It is removed once the scaffold completes.
+const StateName = ".gsi/state.yaml"
+
+// State is the checkpoint of an unfinished scaffold: the settings it ran
+// with, the steps that completed and the step that failed.
+type State struct {
+	GSIVersion   string          `yaml:"gsi_version"`
+	Project      ManifestProject `yaml:"project"`
+	Capabilities map[string]bool `yaml:"capabilities"`
+	Packs        []ManifestPack  `yaml:"packs,omitempty"`
+	Steps        []StateStep     `yaml:"steps"`
+	Failed       string          `yaml:"failed,omitempty"`
+	Fresh        []string        `yaml:"fresh,omitempty"` // files created by tools, relative to the project directory
+	Files        []FileAction    `yaml:"files,omitempty"` // file actions of the completed steps, for the manifest
+
+	carried int // leading Steps completed before this run, which a rollback keeps
+}
+
+// StateStep is a completed step with the hash of the inputs it ran with.
+type StateStep struct {
+	Name   string `yaml:"name"`
+	Inputs string `yaml:"inputs"`
+}
+
+// ReadState loads the checkpoint of the project in dir. It returns nil and
+// no error when there is nothing to resume.
+func ReadState(fsys FS, dir string) (*State, error) {
+	data, err := fsys.ReadFile(filepath.Join(dir, StateName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", StateName, err)
+	}
+	var st State
+	if err := yaml.Unmarshal(data, &st); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", StateName, err)
+	}
+	return &st, nil
+}
+
+// applyTo sets up cfg to continue the checkpointed scaffold. Settings cfg
+// already has (author, description, license, CI, repository URL, packs and
+// individual capabilities) replace the recorded ones.
+func (st *State) applyTo(cfg *Config) {
+	given := *cfg
+	cfg.ProjectName = st.Project.Name
+	cfg.GoModulePath = st.Project.ModulePath
+	cfg.OnlyDocs = st.Project.OnlyDocs
+	st.Project.applyTo(cfg)
+	for _, setting := range []struct{ dst, src *string }{
+		{&cfg.Author, &given.Author},
+		{&cfg.Description, &given.Description},
+		{&cfg.License, &given.License},
+		{&cfg.CI, &given.CI},
+		{&cfg.RepoURL, &given.RepoURL},
+	} {
+		if *setting.src != "" {
+			*setting.dst = *setting.src
+		}
+	}
+
+	cfg.Capabilities = maps.Clone(st.Capabilities)
+	if cfg.Capabilities == nil {
+		cfg.Capabilities = map[string]bool{}
+	}
+	maps.Copy(cfg.Capabilities, given.Capabilities)
+
+	if len(given.Packs) == 0 {
+		cfg.Packs = nil
+		for _, p := range st.Packs {
+			cfg.Packs = append(cfg.Packs, p.String())
+		}
+	}
+}
+
+// checkpoint returns the checkpoint for a run with the current settings. It
+// is only written once a step completes.
+func (s *Scaffolder) checkpoint() *State {
+	return &State{
+		GSIVersion:   s.gsiVersion(),
+		Project:      s.projectRecord(),
+		Capabilities: maps.Clone(s.Config.Capabilities),
+		Packs:        s.packs,
+	}
+}
+
+// stepInputs hashes what step's result depends on: its name, its
+// capability and what its Inputs function reports. A checkpointed step whose
+// inputs hash differs has to run again.
+func (s *Scaffolder) stepInputs(step Step) string {
+	var inputs any
+	if step.Inputs != nil {
+		inputs = step.Inputs(s)
+	}
+	data, err := yaml.Marshal(struct {
+		Step       string `yaml:"step"`
+		Capability string `yaml:"capability,omitempty"`
+		Inputs     any    `yaml:"inputs,omitempty"`
+	}{step.Name, step.Capability, inputs})
+	if err != nil {
+		return ""
+	}
+	return ContentHash(data)
+}
+
+// resumeFrom marks the steps of plan that completed in prev with the same
+// inputs as skipped. A step has to run again when it did not complete, its
+// inputs changed, or a step it comes after runs again. The file actions and
+// fresh files of the skipped steps are carried over.
+func (s *Scaffolder) resumeFrom(prev *State, plan []PlannedStep) {
+	done := make(map[string]string, len(prev.Steps))
+	for _, step := range prev.Steps {
+		done[step.Name] = step.Inputs
+	}
+	kept := map[string]bool{}
+	rerun := map[string]bool{}
+	for i := range plan {
+		p := &plan[i]
+		if p.Skip {
+			continue
+		}
+		inputs, ok := done[p.Name]
+		if ok && inputs != s.stepInputs(p.Step) {
+			s.Logger.Info(fmt.Sprintf("Inputs of %s changed since it completed, running it and the steps that depend on it again", p.Description))
+			ok = false
+		}
+		for _, dep := range p.After {
+			ok = ok && !rerun[dep]
+		}
+		if !ok {
+			rerun[p.Name] = true
+			continue
+		}
+		p.Skip, p.Reason = true, "completed"
+		kept[p.Name] = true
+		s.state.Steps = append(s.state.Steps, StateStep{Name: p.Name, Inputs: inputs})
+	}
+	s.state.carried = len(s.state.Steps)
+
+	for _, a := range prev.Files {
+		if kept[a.Step] {
+			s.Recorder.File(a)
+			s.state.Files = append(s.state.Files, a)
+		}
+	}
+	if len(kept) > 0 {
+		for _, rel := range prev.Fresh {
+			s.markFresh(filepath.Join(s.Config.ProjectDir, rel))
+		}
+	}
+}
+
+// saveCheckpoint records the outcome of step in the state file: completed
+// when err is nil, otherwise failed. It does nothing unless the run
+// checkpoints.
+func (s *Scaffolder) saveCheckpoint(step Step, err error) {
+	st := s.state
+	if st == nil {
+		return
+	}
+	if err != nil {
+		st.Failed = step.Name
+	} else {
+		st.Failed = ""
+		st.Steps = append(st.Steps, StateStep{Name: step.Name, Inputs: s.stepInputs(step)})
+		st.Files = append(st.Files, s.Recorder.StepFiles(step.Name)...)
+	}
+	st.Fresh = st.Fresh[:0]
+	for path := range s.fresh.snapshot() {
+		if rel, err := filepath.Rel(s.Config.ProjectDir, path); err == nil {
+			st.Fresh = append(st.Fresh, filepath.ToSlash(rel))
+		}
+	}
+	slices.Sort(st.Fresh)
+	s.writeCheckpoint()
+}
+
+// keepCheckpoint rewrites the state file after a rollback has removed it or
+// restored an older one. Only the steps completed before the run count as
+// completed, since the rollback undid the rest, so gsi resume runs the
+// failed step and everything after it again.
+func (s *Scaffolder) keepCheckpoint() {
+	st := s.state
+	if st == nil {
+		return
+	}
+	kept := make(map[string]bool, st.carried)
+	for _, step := range st.Steps[:st.carried] {
+		kept[step.Name] = true
+	}
+	st.Steps = st.Steps[:st.carried]
+	st.Files = slices.DeleteFunc(st.Files, func(a FileAction) bool { return !kept[a.Step] })
+	st.Fresh = slices.DeleteFunc(st.Fresh, func(rel string) bool {
+		_, err := os.Lstat(filepath.Join(s.Config.ProjectDir, rel))
+		return err != nil
+	})
+	s.writeCheckpoint()
+}
+
+// writeCheckpoint saves s.state to the state file.
+func (s *Scaffolder) writeCheckpoint() {
+	data, err := yaml.Marshal(s.state)
+	if err == nil {
+		path := filepath.Join(s.Config.ProjectDir, StateName)
+		if err = s.FS.MkdirAll(filepath.Dir(path), 0o755); err == nil {
+			err = s.FS.WriteFile(path, data, 0o644)
+		}
+	}
+	if err != nil {
+		s.Logger.Warning(fmt.Sprintf("Could not save %s: %v", StateName, err))
+	}
+}
+
+// clearCheckpoint removes the state file of a completed scaffold, and its
+// directory when nothing else is in it.
+func (s *Scaffolder) clearCheckpoint() {
+	if s.state == nil {
+		return
+	}
+	s.state = nil
+	path := filepath.Join(s.Config.ProjectDir, StateName)
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		s.Logger.Warning(fmt.Sprintf("Could not remove %s: %v", StateName, err))
+		return
+	}
+	if entries, err := os.ReadDir(filepath.Dir(path)); err == nil && len(entries) == 0 {
+		_ = os.Remove(filepath.Dir(path))
+	}
+}
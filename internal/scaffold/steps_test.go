@@ -31,11 +31,28 @@ func testScaffolder(t *testing.T, dryRun bool) (*Scaffolder, *strings.Builder, *
 	return s, stdout, stderr
 }
 
+// runStep plans the scaffold and executes only the named registered step, so
+// capability guards are applied the same way Run applies them.
+func runStep(t *testing.T, s *Scaffolder, name string) error {
+	t.Helper()
+	plan, err := s.Plan()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range plan {
+		if p.Name == name {
+			return s.execute([]PlannedStep{p})
+		}
+	}
+	t.Fatalf("no registered step %q", name)
+	return nil
+}
+
 func TestStepInstallBmadSkipFlag(t *testing.T) {
 	s, stdout, _ := testScaffolder(t, false)
 	s.Config.Capabilities[CapBmad] = false
 
-	if err := s.stepInstallBmad(); err != nil {
+	if err := runStep(t, s, "bmad"); err != nil {
 		t.Fatal(err)
 	}
 	if !strings.Contains(stdout.String(), "Skipping BMAD") {
@@ -61,7 +78,7 @@ func TestStepGenerateServeCmdIdempotent(t *testing.T) {
 	s, _, _ := testScaffolder(t, false)
 
 	// First call should create
-	if err := s.stepGenerateServeCmd(); err != nil {
+	if err := runStep(t, s, "serve-cmd"); err != nil {
 		t.Fatal(err)
 	}
 	path := filepath.Join(s.Config.ProjectDir, "cmd", "serve.go")
@@ -70,7 +87,7 @@ func TestStepGenerateServeCmdIdempotent(t *testing.T) {
 	}
 
 	// Second call should skip
-	if err := s.stepGenerateServeCmd(); err != nil {
+	if err := runStep(t, s, "serve-cmd"); err != nil {
 		t.Fatal(err)
 	}
 }
@@ -78,7 +95,7 @@ func TestStepGenerateServeCmdIdempotent(t *testing.T) {
 func TestStepGenerateServeCmdDryRun(t *testing.T) {
 	s, _, stderr := testScaffolder(t, true)
 
-	if err := s.stepGenerateServeCmd(); err != nil {
+	if err := runStep(t, s, "serve-cmd"); err != nil {
 		t.Fatal(err)
 	}
 	path := filepath.Join(s.Config.ProjectDir, "cmd", "serve.go")
@@ -94,7 +111,7 @@ func TestStepInitGitSkipFlag(t *testing.T) {
 	s, stdout, _ := testScaffolder(t, false)
 	s.Config.Capabilities[CapGit] = false
 
-	if err := s.stepInitGit(); err != nil {
+	if err := runStep(t, s, "git-init"); err != nil {
 		t.Fatal(err)
 	}
 	if !strings.Contains(stdout.String(), "Skipping git") {
@@ -114,7 +131,7 @@ func TestStepPrintSummaryOnlyDocs(t *testing.T) {
 func TestStepGenerateGoreleaserIdempotent(t *testing.T) {
 	s, _, _ := testScaffolder(t, false)
 
-	if err := s.stepGenerateGoreleaser(); err != nil {
+	if err := runStep(t, s, "goreleaser"); err != nil {
 		t.Fatal(err)
 	}
 	path := filepath.Join(s.Config.ProjectDir, ".goreleaser.yml")
@@ -123,7 +140,7 @@ func TestStepGenerateGoreleaserIdempotent(t *testing.T) {
 	}
 
 	// Second call should skip
-	if err := s.stepGenerateGoreleaser(); err != nil {
+	if err := runStep(t, s, "goreleaser"); err != nil {
 		t.Fatal(err)
 	}
 }
@@ -131,7 +148,7 @@ func TestStepGenerateGoreleaserIdempotent(t *testing.T) {
 func TestStepGenerateGoreleaserDryRun(t *testing.T) {
 	s, _, stderr := testScaffolder(t, true)
 
-	if err := s.stepGenerateGoreleaser(); err != nil {
+	if err := runStep(t, s, "goreleaser"); err != nil {
 		t.Fatal(err)
 	}
 	path := filepath.Join(s.Config.ProjectDir, ".goreleaser.yml")
@@ -146,7 +163,7 @@ func TestStepGenerateGoreleaserDryRun(t *testing.T) {
 func TestStepGenerateDockerfileIdempotent(t *testing.T) {
 	s, _, _ := testScaffolder(t, false)
 
-	if err := s.stepGenerateDockerfile(); err != nil {
+	if err := runStep(t, s, "dockerfile"); err != nil {
 		t.Fatal(err)
 	}
 	path := filepath.Join(s.Config.ProjectDir, "Dockerfile")
@@ -155,7 +172,7 @@ func TestStepGenerateDockerfileIdempotent(t *testing.T) {
 	}
 
 	// Second call should skip
-	if err := s.stepGenerateDockerfile(); err != nil {
+	if err := runStep(t, s, "dockerfile"); err != nil {
 		t.Fatal(err)
 	}
 }
@@ -163,7 +180,7 @@ func TestStepGenerateDockerfileIdempotent(t *testing.T) {
 func TestStepGenerateDockerfileDryRun(t *testing.T) {
 	s, _, stderr := testScaffolder(t, true)
 
-	if err := s.stepGenerateDockerfile(); err != nil {
+	if err := runStep(t, s, "dockerfile"); err != nil {
 		t.Fatal(err)
 	}
 	path := filepath.Join(s.Config.ProjectDir, "Dockerfile")
@@ -178,7 +195,7 @@ func TestStepGenerateDockerfileDryRun(t *testing.T) {
 func TestStepGenerateDockerignoreIdempotent(t *testing.T) {
 	s, _, _ := testScaffolder(t, false)
 
-	if err := s.stepGenerateDockerignore(); err != nil {
+	if err := runStep(t, s, "dockerignore"); err != nil {
 		t.Fatal(err)
 	}
 	path := filepath.Join(s.Config.ProjectDir, ".dockerignore")
@@ -187,7 +204,7 @@ func TestStepGenerateDockerignoreIdempotent(t *testing.T) {
 	}
 
 	// Second call should skip
-	if err := s.stepGenerateDockerignore(); err != nil {
+	if err := runStep(t, s, "dockerignore"); err != nil {
 		t.Fatal(err)
 	}
 }
@@ -195,7 +212,7 @@ func TestStepGenerateDockerignoreIdempotent(t *testing.T) {
 func TestStepGenerateDockerignoreDryRun(t *testing.T) {
 	s, _, stderr := testScaffolder(t, true)
 
-	if err := s.stepGenerateDockerignore(); err != nil {
+	if err := runStep(t, s, "dockerignore"); err != nil {
 		t.Fatal(err)
 	}
 	path := filepath.Join(s.Config.ProjectDir, ".dockerignore")
@@ -221,7 +238,7 @@ func TestStepGenerateGoreleaserDisabled(t *testing.T) {
 	s, stdout, _ := testScaffolder(t, false)
 	s.Config.Capabilities[CapGoreleaser] = false
 
-	if err := s.stepGenerateGoreleaser(); err != nil {
+	if err := runStep(t, s, "goreleaser"); err != nil {
 		t.Fatal(err)
 	}
 	if !strings.Contains(stdout.String(), "Skipping goreleaser") {
@@ -233,7 +250,7 @@ func TestStepGenerateDockerfileDisabled(t *testing.T) {
 	s, stdout, _ := testScaffolder(t, false)
 	s.Config.Capabilities[CapDocker] = false
 
-	if err := s.stepGenerateDockerfile(); err != nil {
+	if err := runStep(t, s, "dockerfile"); err != nil {
 		t.Fatal(err)
 	}
 	if !strings.Contains(stdout.String(), "Skipping Dockerfile") {
@@ -245,7 +262,7 @@ func TestStepGenerateDockerignoreDisabled(t *testing.T) {
 	s, stdout, _ := testScaffolder(t, false)
 	s.Config.Capabilities[CapDocker] = false
 
-	if err := s.stepGenerateDockerignore(); err != nil {
+	if err := runStep(t, s, "dockerignore"); err != nil {
 		t.Fatal(err)
 	}
 	if !strings.Contains(stdout.String(), "Skipping .dockerignore") {
@@ -257,7 +274,7 @@ func TestStepGenerateReleaseWorkflowDisabled(t *testing.T) {
 	s, stdout, _ := testScaffolder(t, false)
 	s.Config.Capabilities[CapRelease] = false
 
-	if err := s.stepGenerateReleaseWorkflow(); err != nil {
+	if err := runStep(t, s, "release-workflow"); err != nil {
 		t.Fatal(err)
 	}
 	if !strings.Contains(stdout.String(), "Skipping release") {
@@ -269,7 +286,7 @@ func TestStepGenerateMockeryDisabled(t *testing.T) {
 	s, stdout, _ := testScaffolder(t, false)
 	s.Config.Capabilities[CapMockery] = false
 
-	if err := s.stepGenerateMockeryConfig(); err != nil {
+	if err := runStep(t, s, "mockery"); err != nil {
 		t.Fatal(err)
 	}
 	if !strings.Contains(stdout.String(), "Skipping mockery") {
@@ -281,7 +298,7 @@ func TestStepGenerateEditorConfigDisabled(t *testing.T) {
 	s, stdout, _ := testScaffolder(t, false)
 	s.Config.Capabilities[CapEditorconfig] = false
 
-	if err := s.stepGenerateEditorConfig(); err != nil {
+	if err := runStep(t, s, "editorconfig"); err != nil {
 		t.Fatal(err)
 	}
 	if !strings.Contains(stdout.String(), "Skipping editorconfig") {
@@ -293,7 +310,7 @@ func TestStepGenerateMakefileDisabled(t *testing.T) {
 	s, stdout, _ := testScaffolder(t, false)
 	s.Config.Capabilities[CapMakefile] = false
 
-	if err := s.stepGenerateMakefile(); err != nil {
+	if err := runStep(t, s, "makefile"); err != nil {
 		t.Fatal(err)
 	}
 	if !strings.Contains(stdout.String(), "Skipping Makefile") {
@@ -305,7 +322,7 @@ func TestStepInitDocsDisabled(t *testing.T) {
 	s, stdout, _ := testScaffolder(t, false)
 	s.Config.Capabilities[CapDocs] = false
 
-	if err := s.stepInitDocs(); err != nil {
+	if err := runStep(t, s, "docs"); err != nil {
 		t.Fatal(err)
 	}
 	if !strings.Contains(stdout.String(), "Skipping docs") {
@@ -318,7 +335,7 @@ func TestStepInitDocsDisabled(t *testing.T) {
 func TestStepGenerateVersionCmdIdempotent(t *testing.T) {
 	s, _, _ := testScaffolder(t, false)
 
-	if err := s.stepGenerateVersionCmd(); err != nil {
+	if err := runStep(t, s, "version-cmd"); err != nil {
 		t.Fatal(err)
 	}
 	path := filepath.Join(s.Config.ProjectDir, "cmd", "version.go")
@@ -335,7 +352,7 @@ func TestStepGenerateVersionCmdIdempotent(t *testing.T) {
 	}
 
 	// Second call should skip
-	if err := s.stepGenerateVersionCmd(); err != nil {
+	if err := runStep(t, s, "version-cmd"); err != nil {
 		t.Fatal(err)
 	}
 }
@@ -343,7 +360,7 @@ func TestStepGenerateVersionCmdIdempotent(t *testing.T) {
 func TestStepGenerateVersionCmdDryRun(t *testing.T) {
 	s, _, stderr := testScaffolder(t, true)
 
-	if err := s.stepGenerateVersionCmd(); err != nil {
+	if err := runStep(t, s, "version-cmd"); err != nil {
 		t.Fatal(err)
 	}
 	path := filepath.Join(s.Config.ProjectDir, "cmd", "version.go")
@@ -360,7 +377,7 @@ func TestStepGenerateVersionCmdDryRun(t *testing.T) {
 func TestStepGenerateConfigCmdIdempotent(t *testing.T) {
 	s, _, _ := testScaffolder(t, false)
 
-	if err := s.stepGenerateConfigCmd(); err != nil {
+	if err := runStep(t, s, "config-cmd"); err != nil {
 		t.Fatal(err)
 	}
 	path := filepath.Join(s.Config.ProjectDir, "cmd", "config.go")
@@ -374,7 +391,7 @@ func TestStepGenerateConfigCmdIdempotent(t *testing.T) {
 	}
 
 	// Second call should skip
-	if err := s.stepGenerateConfigCmd(); err != nil {
+	if err := runStep(t, s, "config-cmd"); err != nil {
 		t.Fatal(err)
 	}
 }
@@ -383,7 +400,7 @@ func TestStepGenerateConfigCmdDisabled(t *testing.T) {
 	s, stdout, _ := testScaffolder(t, false)
 	s.Config.Capabilities[CapConfig] = false
 
-	if err := s.stepGenerateConfigCmd(); err != nil {
+	if err := runStep(t, s, "config-cmd"); err != nil {
 		t.Fatal(err)
 	}
 	if !strings.Contains(stdout.String(), "Skipping config command") {
@@ -394,7 +411,7 @@ func TestStepGenerateConfigCmdDisabled(t *testing.T) {
 func TestStepGenerateConfigCmdDryRun(t *testing.T) {
 	s, _, stderr := testScaffolder(t, true)
 
-	if err := s.stepGenerateConfigCmd(); err != nil {
+	if err := runStep(t, s, "config-cmd"); err != nil {
 		t.Fatal(err)
 	}
 	path := filepath.Join(s.Config.ProjectDir, "cmd", "config.go")
@@ -409,7 +426,7 @@ func TestStepGenerateConfigCmdDryRun(t *testing.T) {
 func TestStepGenerateConfigPkgIdempotent(t *testing.T) {
 	s, _, _ := testScaffolder(t, false)
 
-	if err := s.stepGenerateConfigPkg(); err != nil {
+	if err := runStep(t, s, "config-pkg"); err != nil {
 		t.Fatal(err)
 	}
 	path := filepath.Join(s.Config.ProjectDir, "internal", "config", "config.go")
@@ -426,7 +443,7 @@ func TestStepGenerateConfigPkgIdempotent(t *testing.T) {
 	}
 
 	// Second call should skip
-	if err := s.stepGenerateConfigPkg(); err != nil {
+	if err := runStep(t, s, "config-pkg"); err != nil {
 		t.Fatal(err)
 	}
 }
@@ -435,7 +452,7 @@ func TestStepGenerateConfigPkgDisabled(t *testing.T) {
 	s, stdout, _ := testScaffolder(t, false)
 	s.Config.Capabilities[CapConfig] = false
 
-	if err := s.stepGenerateConfigPkg(); err != nil {
+	if err := runStep(t, s, "config-pkg"); err != nil {
 		t.Fatal(err)
 	}
 	if !strings.Contains(stdout.String(), "Skipping config package") {
@@ -446,7 +463,7 @@ func TestStepGenerateConfigPkgDisabled(t *testing.T) {
 func TestStepGenerateConfigInitIdempotent(t *testing.T) {
 	s, _, _ := testScaffolder(t, false)
 
-	if err := s.stepGenerateConfigInit(); err != nil {
+	if err := runStep(t, s, "config-init"); err != nil {
 		t.Fatal(err)
 	}
 	path := filepath.Join(s.Config.ProjectDir, "cmd", "config_init.go")
@@ -460,7 +477,7 @@ func TestStepGenerateConfigInitIdempotent(t *testing.T) {
 	}
 
 	// Second call should skip
-	if err := s.stepGenerateConfigInit(); err != nil {
+	if err := runStep(t, s, "config-init"); err != nil {
 		t.Fatal(err)
 	}
 }
@@ -469,7 +486,7 @@ func TestStepGenerateConfigInitDisabled(t *testing.T) {
 	s, stdout, _ := testScaffolder(t, false)
 	s.Config.Capabilities[CapConfig] = false
 
-	if err := s.stepGenerateConfigInit(); err != nil {
+	if err := runStep(t, s, "config-init"); err != nil {
 		t.Fatal(err)
 	}
 	if !strings.Contains(stdout.String(), "Skipping config init") {
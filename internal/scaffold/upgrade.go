@@ -0,0 +1,250 @@
+package scaffold
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/joescharf/gsi/internal/templates"
+)
+
+// Upgrade outcomes for a single file.
+const (
+	UpgradeCurrent    = "up to date" // the template has not changed
+	UpgradeUpdated    = "updated"    // the file was unmodified and is replaced
+	UpgradeMerged     = "merged"     // local edits and template changes merged cleanly
+	UpgradeConflict   = "conflict"   // written with conflict markers
+	UpgradeNoBaseline = "no baseline"
+	UpgradeMissing    = "missing"          // deleted from the project; left alone
+	UpgradeRemoved    = "removed upstream" // this gsi no longer generates the file; left alone
+	UpgradeAdded      = "added"            // generated by this gsi but not before; created
+	UpgradeUntracked  = "untracked"        // generated by this gsi but already there; resolved by --on-conflict
+)
+
+// UpgradeResult is the outcome of upgrading one manifest file.
+type UpgradeResult struct {
+	Path   string
+	Status string
+}
+
+// Upgrade re-renders every file recorded in the manifest of the project in
+// Config.ProjectDir with the current templates. Unmodified files are
+// replaced; edited files are three-way merged against the baseline render
+// saved when gsi last wrote them. Edited files without a baseline (projects
+// scaffolded before baselines were recorded) go through Config.OnConflict.
+// Files the current templates generate for the recorded capabilities but the
+// manifest lacks are created, and manifest files they no longer generate are
+// reported and left alone. The project's packs are re-fetched at the refs
+// recorded in the manifest unless Config.Packs names others.
+func (s *Scaffolder) Upgrade() ([]UpgradeResult, error) {
+	cfg := &s.Config
+	if cfg.ProjectDir == "" {
+		dir, err := os.Getwd()
+		if err != nil {
+			return nil, fmt.Errorf("getting current directory: %w", err)
+		}
+		cfg.ProjectDir = dir
+	}
+	s.Executor.Dir = cfg.ProjectDir
+
+	s.loadManifest()
+	m := s.manifest
+	if m == nil {
+		return nil, fmt.Errorf("no %s in %s; upgrade only works on projects scaffolded by gsi", ManifestName, cfg.ProjectDir)
+	}
+	cfg.ProjectName = m.Project.Name
+	cfg.GoModulePath = m.Project.ModulePath
+	m.Project.applyTo(cfg)
+	cfg.OnlyDocs = m.Project.OnlyDocs
+	cfg.Capabilities = m.Capabilities
+	if err := s.resolveProjectDefaults(); err != nil {
+		return nil, err
+	}
+	s.usePacksFrom(m)
+	if err := s.loadPacks(); err != nil {
+		return nil, err
+	}
+
+	s.Logger.Plain("")
+	s.Logger.Info(fmt.Sprintf("Upgrading project scaffolded by gsi %s:", m.GSIVersion))
+	s.Logger.Plain("  Project Name:  " + cfg.ProjectName)
+	s.Logger.Plain("  Project Dir:   " + cfg.ProjectDir)
+	s.Logger.Plain("  Module Path:   " + cfg.GoModulePath)
+	s.printPacks()
+	if cfg.DryRun {
+		s.Logger.Plain("  \033[1;33mMode:          DRY-RUN\033[0m")
+	}
+	s.Logger.Plain("")
+
+	planned, err := s.plannedFiles()
+	if err != nil {
+		return nil, err
+	}
+	s.Recorder.SetStep("upgrade")
+	results := make([]UpgradeResult, 0, len(m.Files))
+	for _, f := range m.Files {
+		if _, ok := planned[f.Path]; !ok {
+			s.Logger.Warning(f.Path + " is no longer generated by gsi, leaving it as it is")
+			results = append(results, UpgradeResult{Path: f.Path, Status: UpgradeRemoved})
+			continue
+		}
+		status, err := s.upgradeFile(f)
+		if err != nil {
+			return results, err
+		}
+		results = append(results, UpgradeResult{Path: f.Path, Status: status})
+	}
+	for _, rel := range slices.Sorted(maps.Keys(planned)) {
+		if _, ok := m.File(rel); ok {
+			continue
+		}
+		status, err := s.addFile(planned[rel], rel)
+		if err != nil {
+			return results, err
+		}
+		if status != "" {
+			results = append(results, UpgradeResult{Path: rel, Status: status})
+		}
+	}
+
+	s.Recorder.SetStep("manifest")
+	if err := s.stepWriteManifest(); err != nil {
+		return results, err
+	}
+	s.printUpgradeSummary(results)
+	return results, nil
+}
+
+// plannedFiles returns the pack files the current plan generates for the
+// project, by their slash-separated path.
+func (s *Scaffolder) plannedFiles() (map[string]templates.PackFile, error) {
+	pack, err := s.templatePack()
+	if err != nil {
+		return nil, err
+	}
+	plan, err := s.Plan()
+	if err != nil {
+		return nil, err
+	}
+	run := map[string]bool{}
+	for _, p := range plan {
+		run[p.Name] = !p.Skip
+	}
+	data := s.templateData()
+	files := map[string]templates.PackFile{}
+	for _, f := range pack.Files {
+		if !run[f.Name] {
+			continue
+		}
+		rel, err := f.RenderPath(data)
+		if err != nil {
+			return nil, fmt.Errorf("pack %s: %w", pack.Name, err)
+		}
+		files[rel] = f
+	}
+	return files, nil
+}
+
+// addFile writes a file the project's manifest does not have yet, returning
+// its upgrade status, or "" when its template renders nothing.
+func (s *Scaffolder) addFile(f templates.PackFile, rel string) (string, error) {
+	rendered, err := s.renderer.Render(f.Template, s.templateData())
+	if err != nil {
+		return "", fmt.Errorf("rendering template %s: %w", f.Template, err)
+	}
+	if strings.TrimSpace(rendered) == "" {
+		return "", nil
+	}
+	status := UpgradeAdded
+	if _, err := s.FS.Stat(filepath.Join(s.Config.ProjectDir, filepath.FromSlash(rel))); err == nil {
+		status = UpgradeUntracked
+	}
+	return status, s.writePackFile(f, rel)
+}
+
+// upgradeFile re-renders one manifest file and brings the project copy up to
+// date, returning its upgrade status.
+func (s *Scaffolder) upgradeFile(f ManifestFile) (string, error) {
+	path := filepath.Join(s.Config.ProjectDir, filepath.FromSlash(f.Path))
+	rendered, err := s.renderer.Render(f.Template, s.templateData())
+	if err != nil {
+		s.Logger.Warning(fmt.Sprintf("Skipping %s: %v", f.Path, err))
+		return UpgradeRemoved, nil
+	}
+	generated := stampContent(path, []byte(rendered))
+	basePath := filepath.Join(s.Config.ProjectDir, BaselineDir, filepath.FromSlash(f.Path))
+	if ContentHash(generated) == f.Hash {
+		s.Logger.VerboseMsg(f.Path + " is up to date")
+		// Projects scaffolded before baselines were kept get one now
+		if _, err := s.FS.Stat(basePath); err != nil {
+			return UpgradeCurrent, s.writer().saveBaseline(path, f.Template, generated)
+		}
+		return UpgradeCurrent, nil
+	}
+
+	current, err := s.FS.ReadFile(path)
+	if err != nil {
+		s.Logger.Warning(f.Path + " no longer exists, leaving it out")
+		return UpgradeMissing, nil
+	}
+	mode := os.FileMode(0o644)
+	if info, err := s.FS.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	w := s.writer()
+	record := func(action string) {
+		s.Recorder.File(FileAction{Path: f.Path, Action: action, Template: f.Template, Mode: fileModeString(mode), Hash: ContentHash(generated)})
+	}
+	save := func(data []byte, action string) error {
+		record(action)
+		if err := w.write(path, data, mode, action); err != nil {
+			return err
+		}
+		return w.saveBaseline(path, f.Template, generated)
+	}
+
+	if ContentHash(current) == f.Hash {
+		return UpgradeUpdated, save(generated, ActionOverwrite)
+	}
+
+	base, err := s.FS.ReadFile(basePath)
+	if err != nil || ContentHash(base) != f.Hash {
+		// Without the original render there is nothing to merge against
+		s.Logger.Warning(f.Path + " has local changes and no baseline to merge against")
+		if err := w.put(path, f.Template, generated, mode, s.Config.OnConflict); err != nil {
+			return "", err
+		}
+		return UpgradeNoBaseline, nil
+	}
+
+	merged, conflicts := mergeThreeWay(base, current, generated)
+	if conflicts {
+		s.Logger.Warning(f.Path + " has conflicting changes; resolve the conflict markers")
+		return UpgradeConflict, save(merged, ActionMerge)
+	}
+	return UpgradeMerged, save(merged, ActionMerge)
+}
+
+// printUpgradeSummary logs one line per file that changed, then totals.
+func (s *Scaffolder) printUpgradeSummary(results []UpgradeResult) {
+	counts := map[string]int{}
+	s.Logger.Plain("")
+	for _, r := range results {
+		counts[r.Status]++
+		if r.Status != UpgradeCurrent {
+			s.Logger.Plain(fmt.Sprintf("  %-16s %s", r.Status, r.Path))
+		}
+	}
+	s.Logger.Plain("")
+	summary := fmt.Sprintf("%d updated, %d merged, %d added, %d up to date",
+		counts[UpgradeUpdated], counts[UpgradeMerged], counts[UpgradeAdded], counts[UpgradeCurrent])
+	if n := counts[UpgradeConflict] + counts[UpgradeNoBaseline]; n > 0 {
+		s.Logger.Warning(fmt.Sprintf("%s, %d need attention", summary, n))
+		return
+	}
+	s.Logger.Success(summary)
+}
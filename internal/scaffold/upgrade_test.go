@@ -0,0 +1,207 @@
+package scaffold
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// upgradeScaffolder scaffolds main.go into a temp project, then rewinds it to
+// look as if an older gsi had produced it: the baseline and manifest hold a
+// render with an extra comment line. edit turns that old render into the
+// content on disk.
+func upgradeScaffolder(t *testing.T, edit func(old string) string) (*Scaffolder, string) {
+	t.Helper()
+	s, _, _ := testScaffolder(t, false)
+	path := filepath.Join(s.Config.ProjectDir, "main.go")
+	if err := s.writeTemplate(path, "main_go.tmpl"); err != nil {
+		t.Fatal(err)
+	}
+	current, _ := os.ReadFile(path)
+	old := strings.Replace(string(current), "// Set by goreleaser ldflags.\n", "// Set by goreleaser ldflags.\n// Old comment.\n", 1)
+
+	baseline := filepath.Join(s.Config.ProjectDir, BaselineDir, "main.go")
+	if err := os.WriteFile(baseline, []byte(old), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(edit(old)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	s.Recorder.Files = []FileAction{{Path: "main.go", Action: ActionCreate, Template: "main_go.tmpl", Hash: ContentHash([]byte(old))}}
+	if err := s.stepWriteManifest(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Start the upgrade from a clean Scaffolder, as the command does
+	u, _, _ := testScaffolder(t, false)
+	u.Config.ProjectDir = s.Config.ProjectDir
+	return u, path
+}
+
+func upgradeStatus(t *testing.T, s *Scaffolder) string {
+	t.Helper()
+	results, err := s.Upgrade()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range results {
+		if r.Path == "main.go" {
+			return r.Status
+		}
+	}
+	t.Fatalf("no result for main.go in %+v", results)
+	return ""
+}
+
+func TestUpgradeUnmodifiedFile(t *testing.T) {
+	s, path := upgradeScaffolder(t, func(old string) string { return old })
+
+	if status := upgradeStatus(t, s); status != UpgradeUpdated {
+		t.Errorf("expected %q, got %q", UpgradeUpdated, status)
+	}
+	content, _ := os.ReadFile(path)
+	if strings.Contains(string(content), "Old comment") || !GeneratedUnmodified(content) {
+		t.Errorf("expected the current render, got %q", content)
+	}
+
+	// The manifest and baseline now describe the new render
+	m, _ := ReadManifest(OSFS{}, s.Config.ProjectDir)
+	if m.Modified("main.go", content) {
+		t.Error("manifest hash should match the upgraded file")
+	}
+	base, _ := os.ReadFile(filepath.Join(s.Config.ProjectDir, BaselineDir, "main.go"))
+	if string(base) != string(content) {
+		t.Error("baseline should hold the new render")
+	}
+}
+
+func TestUpgradeMergesLocalEdits(t *testing.T) {
+	s, path := upgradeScaffolder(t, func(old string) string {
+		return strings.Replace(old, `date    = "unknown"`, `date    = "today"`, 1)
+	})
+
+	if status := upgradeStatus(t, s); status != UpgradeMerged {
+		t.Errorf("expected %q, got %q", UpgradeMerged, status)
+	}
+	content, _ := os.ReadFile(path)
+	if strings.Contains(string(content), "Old comment") {
+		t.Error("template change should be applied")
+	}
+	if !strings.Contains(string(content), `date    = "today"`) {
+		t.Error("local edit should be kept")
+	}
+}
+
+func TestUpgradeConflict(t *testing.T) {
+	s, path := upgradeScaffolder(t, func(old string) string {
+		return strings.Replace(old, "// Old comment.\n", "// My comment.\n", 1)
+	})
+
+	if status := upgradeStatus(t, s); status != UpgradeConflict {
+		t.Errorf("expected %q, got %q", UpgradeConflict, status)
+	}
+	content, _ := os.ReadFile(path)
+	if !strings.Contains(string(content), "<<<<<<< existing\n// My comment.\n=======\n>>>>>>> gsi\n") {
+		t.Errorf("expected conflict markers, got:\n%s", content)
+	}
+}
+
+func TestUpgradeWithoutBaselineUsesPolicy(t *testing.T) {
+	s, path := upgradeScaffolder(t, func(old string) string { return old + "// edit\n" })
+	if err := os.Remove(filepath.Join(s.Config.ProjectDir, BaselineDir, "main.go")); err != nil {
+		t.Fatal(err)
+	}
+	before, _ := os.ReadFile(path)
+
+	if status := upgradeStatus(t, s); status != UpgradeNoBaseline {
+		t.Errorf("expected %q, got %q", UpgradeNoBaseline, status)
+	}
+	after, _ := os.ReadFile(path)
+	if string(after) != string(before) {
+		t.Error("default skip policy should leave the edited file alone")
+	}
+}
+
+func TestUpgradeDryRun(t *testing.T) {
+	s, path := upgradeScaffolder(t, func(old string) string { return old })
+	before, _ := os.ReadFile(path)
+	s.Config.DryRun = true
+	s.FS = NewMemFS(OSFS{})
+
+	if status := upgradeStatus(t, s); status != UpgradeUpdated {
+		t.Errorf("expected %q, got %q", UpgradeUpdated, status)
+	}
+	after, _ := os.ReadFile(path)
+	if string(after) != string(before) {
+		t.Error("dry-run upgrade should not modify files")
+	}
+}
+
+func TestUpgradeRequiresManifest(t *testing.T) {
+	s, _, _ := testScaffolder(t, false)
+	if _, err := s.Upgrade(); err == nil || !strings.Contains(err.Error(), ManifestName) {
+		t.Errorf("expected missing manifest error, got %v", err)
+	}
+}
+
+func TestUpgradeAddsNewFiles(t *testing.T) {
+	s, _ := upgradeScaffolder(t, func(old string) string { return old })
+	dir := s.Config.ProjectDir
+
+	results, err := s.Upgrade()
+	if err != nil {
+		t.Fatal(err)
+	}
+	status := map[string]string{}
+	for _, r := range results {
+		status[r.Path] = r.Status
+	}
+	if status["Dockerfile"] != UpgradeAdded {
+		t.Errorf("expected the Dockerfile to be added, got %q", status["Dockerfile"])
+	}
+	if _, err := os.Stat(filepath.Join(dir, "Dockerfile")); err != nil {
+		t.Errorf("expected the Dockerfile to be written: %v", err)
+	}
+	if _, ok := status["ui/build.sh"]; ok {
+		t.Error("files of disabled capabilities should not be added")
+	}
+	m, _ := ReadManifest(OSFS{}, dir)
+	if _, ok := m.File("Dockerfile"); !ok {
+		t.Error("expected the added file in the manifest")
+	}
+}
+
+func TestUpgradeReportsRemovedUpstream(t *testing.T) {
+	s, _ := upgradeScaffolder(t, func(old string) string { return old })
+	dir := s.Config.ProjectDir
+	old := filepath.Join(dir, "old.cfg")
+	if err := os.WriteFile(old, []byte("kept\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// An older gsi generated old.cfg from a template this one no longer has
+	w, _, _ := testScaffolder(t, false)
+	w.Config.ProjectDir = dir
+	w.loadManifest()
+	w.Recorder.Files = []FileAction{{Path: "old.cfg", Action: ActionCreate, Template: "old_cfg.tmpl", Hash: ContentHash([]byte("kept\n"))}}
+	if err := w.stepWriteManifest(); err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := s.Upgrade()
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, r := range results {
+		if r.Path == "old.cfg" {
+			found = r.Status == UpgradeRemoved
+		}
+	}
+	if !found {
+		t.Errorf("expected old.cfg to be reported as %q, got %+v", UpgradeRemoved, results)
+	}
+	if content, _ := os.ReadFile(old); string(content) != "kept\n" {
+		t.Errorf("expected old.cfg to be left alone, got %q", content)
+	}
+}
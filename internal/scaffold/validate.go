@@ -0,0 +1,352 @@
+package scaffold
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/format"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"io"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/joescharf/gsi/internal/templates"
+	"github.com/pelletier/go-toml/v2"
+	"go.yaml.in/yaml/v3"
+	"mvdan.cc/sh/v3/syntax"
+)
+
+// TemplateProblem is a template that failed to render, or rendered output
+// that does not parse as its file type.
+type TemplateProblem struct {
+	Template     string // template name
+	Path         string // output path, relative to the project
+	Capabilities string // settings the template was rendered with (see validationSets)
+	Line         int    // line in the template (render errors) or output; 0 if unknown
+	Column       int    // column on Line; 0 if unknown
+	Err          string
+}
+
+func (p TemplateProblem) String() string {
+	loc := p.Path
+	if loc == "" {
+		loc = p.Template
+	} else {
+		loc += " (" + p.Template + ")"
+	}
+	if p.Line > 0 {
+		loc += ":" + strconv.Itoa(p.Line)
+		if p.Column > 0 {
+			loc += ":" + strconv.Itoa(p.Column)
+		}
+	}
+	return fmt.Sprintf("%s: %s [%s]", loc, p.Err, p.Capabilities)
+}
+
+// TemplateReport summarizes a ValidateTemplates run.
+type TemplateReport struct {
+	Files    int // pack files validated
+	Renders  int // distinct rendered outputs checked
+	Sets     int // combinations of settings rendered
+	Problems []TemplateProblem
+}
+
+// validationSet is a named combination of capabilities, CI provider and
+// license used for validation renders. Empty CI and License keep the sample
+// project's.
+type validationSet struct {
+	Name    string
+	Caps    map[string]bool
+	CI      string
+	License string
+}
+
+// validationSets returns the settings templates are validated with: the
+// defaults, everything on, everything off, the defaults with each capability
+// flipped, and the defaults with every capability on under each CI provider
+// and each license. Together they reach both branches of every
+// single-capability condition and every provider's and license's templates.
+func validationSets() []validationSet {
+	sets := []validationSet{
+		{Name: "defaults", Caps: DefaultCapabilities()},
+		{Name: "all", Caps: allCapabilities(true)},
+		{Name: "none", Caps: allCapabilities(false)},
+	}
+	for _, name := range CapabilityNames() {
+		caps := DefaultCapabilities()
+		caps[name] = !caps[name]
+		sign := "+"
+		if !caps[name] {
+			sign = "-"
+		}
+		sets = append(sets, validationSet{Name: "defaults " + sign + name, Caps: caps})
+	}
+	for _, ci := range CIProviders {
+		sets = append(sets, validationSet{Name: "all --ci " + ci, Caps: allCapabilities(true), CI: ci})
+	}
+	for _, license := range Licenses {
+		sets = append(sets, validationSet{Name: "all --license " + license, Caps: allCapabilities(true), License: license})
+	}
+	return sets
+}
+
+// ValidateTemplates renders every file of the pack (the built-in pack layered
+// with Config.Packs) with sample project data across validationSets, and
+// checks each distinct output by file type: Go files must parse and be
+// gofmt-formatted, YAML, JSON, TOML and INI files must parse, and shell
+// scripts must parse as shell. Other file types are only checked to render.
+// The sample description and author replace the configured ones and hold
+// characters that need quoting, so templates that insert them unescaped are
+// caught.
+// names, when given, limits the run to pack files with those step or
+// template names.
+func (s *Scaffolder) ValidateTemplates(names []string) (*TemplateReport, error) {
+	cfg := &s.Config
+	if cfg.ProjectName == "" {
+		cfg.ProjectName = "sample-app"
+	}
+	if cfg.GoModulePath == "" {
+		cfg.GoModulePath = DefaultModulePath("github.com/example", cfg.ProjectName)
+	}
+	// always render free text that needs quoting in YAML and TOML
+	cfg.Author = `Jane "JD" Doe: Example \ Co <jane@example.com>`
+	cfg.Description = `Sample "demo": application with a \ backslash`
+	if err := s.resolveProjectDefaults(); err != nil {
+		return nil, err
+	}
+	if err := s.loadPacks(); err != nil {
+		return nil, err
+	}
+	pack, err := s.templatePack()
+	if err != nil {
+		return nil, err
+	}
+
+	var files []templates.PackFile
+	for _, f := range pack.Files {
+		if len(names) == 0 || slices.Contains(names, f.Name) || slices.Contains(names, f.Template) {
+			files = append(files, f)
+		}
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no pack files match %s", strings.Join(names, ", "))
+	}
+
+	report := &TemplateReport{Files: len(files)}
+	for _, f := range files {
+		if f.Capability != "" && !IsCapability(f.Capability) {
+			report.Problems = append(report.Problems, TemplateProblem{Template: f.Template, Path: f.Path,
+				Err: fmt.Sprintf("unknown capability %q", f.Capability)})
+		}
+		if f.CI != "" && !IsCIProvider(f.CI) {
+			report.Problems = append(report.Problems, TemplateProblem{Template: f.Template, Path: f.Path,
+				Err: fmt.Sprintf("unknown CI provider %q", f.CI)})
+		}
+	}
+	sets := validationSets()
+	report.Sets = len(sets)
+	checked := make(map[string]bool)
+	sampleCI, sampleLicense := cfg.CI, cfg.License
+	for _, set := range sets {
+		cfg.Capabilities, cfg.CI, cfg.License = set.Caps, cmp.Or(set.CI, sampleCI), cmp.Or(set.License, sampleLicense)
+		data := s.templateData()
+		for _, f := range files {
+			if f.Capability != "" && !set.Caps[f.Capability] {
+				continue
+			}
+			if f.CI != "" && f.CI != cfg.CI {
+				continue
+			}
+			problem := TemplateProblem{Template: f.Template, Capabilities: set.Name}
+			rel, err := f.RenderPath(data)
+			if err != nil {
+				problem.Err = err.Error()
+				report.Problems = appendProblem(report.Problems, problem)
+				continue
+			}
+			problem.Path = rel
+			out, err := s.renderer.Render(f.Template, data)
+			if err != nil {
+				problem.Line, problem.Err = templateErrorLine(err), err.Error()
+				report.Problems = appendProblem(report.Problems, problem)
+				continue
+			}
+			key := rel + "\x00" + out
+			if checked[key] || strings.TrimSpace(out) == "" {
+				continue
+			}
+			checked[key] = true
+			report.Renders++
+			if line, col, err := validateOutput(rel, []byte(out)); err != nil {
+				problem.Line, problem.Column, problem.Err = line, col, err.Error()
+				report.Problems = appendProblem(report.Problems, problem)
+			}
+		}
+	}
+	return report, nil
+}
+
+// appendProblem adds p unless the same error was already reported for the
+// same file under another capability set.
+func appendProblem(problems []TemplateProblem, p TemplateProblem) []TemplateProblem {
+	for _, q := range problems {
+		if q.Template == p.Template && q.Path == p.Path && q.Line == p.Line && q.Column == p.Column && q.Err == p.Err {
+			return problems
+		}
+	}
+	return append(problems, p)
+}
+
+var (
+	templateErrLine = regexp.MustCompile(`^template: [^:]+:(\d+)`)
+	lineNumber      = regexp.MustCompile(`line (\d+)`)
+)
+
+func templateErrorLine(err error) int {
+	if m := templateErrLine.FindStringSubmatch(err.Error()); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		return n
+	}
+	return 0
+}
+
+// validateOutput checks content by the file type of rel and returns the
+// offending line and column (0 if unknown) with the error.
+func validateOutput(rel string, content []byte) (line, col int, err error) {
+	switch ext := path.Ext(rel); ext {
+	case ".go":
+		line, err = validateGo(rel, content)
+	case ".yml", ".yaml":
+		line, err = validateYAML(content)
+	case ".json":
+		line, err = validateJSON(content)
+	case ".toml":
+		var v any
+		if err = toml.Unmarshal(content, &v); err != nil {
+			var derr *toml.DecodeError
+			if errors.As(err, &derr) {
+				line, col = derr.Position()
+			}
+		}
+	case ".ini":
+		line, err = validateINI(content)
+	case ".sh", ".bash":
+		return validateShell(rel, content)
+	}
+	return line, col, err
+}
+
+func validateGo(rel string, content []byte) (int, error) {
+	if _, err := parser.ParseFile(token.NewFileSet(), rel, content, parser.ParseComments); err != nil {
+		var list scanner.ErrorList
+		if errors.As(err, &list) && len(list) > 0 {
+			return list[0].Pos.Line, errors.New(list[0].Msg)
+		}
+		return 0, err
+	}
+	formatted, err := format.Source(content)
+	if err != nil {
+		return 0, err
+	}
+	if !bytes.Equal(formatted, content) {
+		return firstDifferentLine(content, formatted), errors.New("not gofmt-formatted")
+	}
+	return 0, nil
+}
+
+func firstDifferentLine(a, b []byte) int {
+	al, bl := strings.Split(string(a), "\n"), strings.Split(string(b), "\n")
+	for i := range min(len(al), len(bl)) {
+		if al[i] != bl[i] {
+			return i + 1
+		}
+	}
+	return min(len(al), len(bl)) + 1
+}
+
+func validateYAML(content []byte) (int, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(content))
+	for {
+		var node yaml.Node
+		err := dec.Decode(&node)
+		if errors.Is(err, io.EOF) {
+			return 0, nil
+		}
+		if err != nil {
+			return errorLine(err), err
+		}
+	}
+}
+
+func validateJSON(content []byte) (int, error) {
+	var v any
+	if err := json.Unmarshal(content, &v); err != nil {
+		var serr *json.SyntaxError
+		if errors.As(err, &serr) {
+			return bytes.Count(content[:serr.Offset], []byte("\n")) + 1, err
+		}
+		return 0, err
+	}
+	return 0, nil
+}
+
+// validateINI accepts blank lines, ; and # comments, [section] headers,
+// key = value and key: value pairs, and indented continuation lines.
+func validateINI(content []byte) (int, error) {
+	sc := bufio.NewScanner(bytes.NewReader(content))
+	for n := 1; sc.Scan(); n++ {
+		line := sc.Text()
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "", trimmed[0] == ';', trimmed[0] == '#':
+		case trimmed[0] == '[':
+			if !strings.HasSuffix(trimmed, "]") || len(trimmed) < 3 {
+				return n, fmt.Errorf("malformed section header %q", trimmed)
+			}
+		case line[0] == ' ' || line[0] == '\t':
+		case strings.IndexAny(trimmed, "=:") > 0:
+		default:
+			return n, fmt.Errorf("expected key = value, got %q", trimmed)
+		}
+	}
+	return 0, sc.Err()
+}
+
+// validateShell parses content as a shell script: POSIX sh when its
+// shebang names sh, otherwise bash.
+func validateShell(rel string, content []byte) (int, int, error) {
+	lang := syntax.LangBash
+	if first, _, _ := bytes.Cut(content, []byte("\n")); shShebang.Match(first) {
+		lang = syntax.LangPOSIX
+	}
+	_, err := syntax.NewParser(syntax.Variant(lang)).Parse(bytes.NewReader(content), rel)
+	var perr syntax.ParseError
+	if errors.As(err, &perr) {
+		return int(perr.Pos.Line()), int(perr.Pos.Col()), errors.New(perr.Text)
+	}
+	var lerr syntax.LangError
+	if errors.As(err, &lerr) {
+		return int(lerr.Pos.Line()), int(lerr.Pos.Col()), fmt.Errorf("%s is not supported by %s", lerr.Feature, lerr.LangUsed)
+	}
+	return 0, 0, err
+}
+
+// shShebang matches a first line running the script with POSIX sh.
+var shShebang = regexp.MustCompile(`^#!\s*(/usr/bin/env\s+sh|/bin/sh|/usr/bin/sh)\s*$`)
+
+// errorLine extracts "line N" from a parser error message.
+func errorLine(err error) int {
+	if m := lineNumber.FindStringSubmatch(err.Error()); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		return n
+	}
+	return 0
+}
@@ -0,0 +1,94 @@
+package scaffold
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateTemplatesBuiltin(t *testing.T) {
+	s, _, _ := testScaffolder(t, false)
+	report, err := s.ValidateTemplates(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range report.Problems {
+		t.Errorf("built-in template problem: %s", p)
+	}
+	if report.Sets != len(validationSets()) || report.Renders < report.Files {
+		t.Errorf("unexpected report %+v", report)
+	}
+}
+
+func TestValidateTemplatesReportsProblems(t *testing.T) {
+	s, _, _ := testScaffolder(t, false)
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "cmd_version.go.tmpl"), "package cmd\n\nfunc x( {\n")
+	writeTestFile(t, filepath.Join(dir, "mockery_yml.tmpl"), "a: [\n")
+	writeTestFile(t, filepath.Join(dir, "editorconfig.tmpl"), "root = true\n{{.Nope}}\n")
+	s.Config.Packs = []string{dir}
+	t.Cleanup(func() { s.Config.Packs = nil; _ = s.loadPacks() })
+
+	report, err := s.ValidateTemplates([]string{"version-cmd", "mockery_yml.tmpl", "editorconfig", "makefile"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Files != 4 {
+		t.Errorf("expected 4 files selected, got %d", report.Files)
+	}
+	want := map[string]string{
+		"cmd_version.go.tmpl": "cmd/version.go (cmd_version.go.tmpl):3: expected ')'",
+		"mockery_yml.tmpl":    ".mockery.yml (mockery_yml.tmpl):1: yaml:",
+		"editorconfig.tmpl":   ".editorconfig (editorconfig.tmpl):2: template: editorconfig.tmpl:2",
+	}
+	if len(report.Problems) != len(want) {
+		t.Fatalf("expected %d problems, got %v", len(want), report.Problems)
+	}
+	for _, p := range report.Problems {
+		if !strings.Contains(p.String(), want[p.Template]) {
+			t.Errorf("problem %q does not contain %q", p, want[p.Template])
+		}
+	}
+
+	if _, err := s.ValidateTemplates([]string{"nope"}); err == nil {
+		t.Error("expected an error for unknown names")
+	}
+}
+
+func TestValidateOutput(t *testing.T) {
+	tests := []struct {
+		path    string
+		content string
+		line    int // 0: valid
+		col     int // 0: not checked
+	}{
+		{"main.go", "package main\n\nfunc main() {}\n", 0, 0},
+		{"main.go", "package main\n\nfunc main() {\n", 3, 0},
+		{"main.go", "package main\nfunc main()  {}\n", 2, 0},
+		{"a.yml", "a: 1\n---\nb: 2\n", 0, 0},
+		{"a.yaml", "a: 1\nb: [\n", 2, 0},
+		{"a.json", `{"a": 1}`, 0, 0},
+		{"a.json", "{\n\"a\": }", 2, 0},
+		{"a.toml", "[a]\nb = 1\n", 0, 0},
+		{"a.toml", "[a]\nb = \n", 2, 0},
+		{"a.ini", "; c\n[s]\nk = v\nk2: v\n  more\n", 0, 0},
+		{"a.ini", "[s]\njunk\n", 2, 0},
+		{"a.sh", "#!/bin/bash\necho hi\n", 0, 0},
+		{"a.sh", "echo\nif then\n", 2, 1},
+		{"a.sh", "#!/bin/bash\na=(1 2)\n", 0, 0},
+		{"a.sh", "#!/bin/sh\necho\na=(1 2)\n", 3, 3},
+		{"README.md", "{{ anything", 0, 0},
+	}
+	for _, tt := range tests {
+		line, col, err := validateOutput(tt.path, []byte(tt.content))
+		if tt.line == 0 && err != nil {
+			t.Errorf("%s %q: unexpected error %v", tt.path, tt.content, err)
+		}
+		if tt.line != 0 && (err == nil || line != tt.line) {
+			t.Errorf("%s %q: got line %d, %v; want an error on line %d", tt.path, tt.content, line, err, tt.line)
+		}
+		if tt.col != 0 && col != tt.col {
+			t.Errorf("%s %q: got column %d, want %d", tt.path, tt.content, col, tt.col)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package templates
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+)
+
+// funcs is the function library available to every template and to pack
+// file paths.
+var funcs = template.FuncMap{
+	"kebab":   Kebab,
+	"snake":   Snake,
+	"camel":   Camel,
+	"pascal":  Pascal,
+	"envVar":  EnvVar,
+	"quote":   quote,
+	"indent":  indent,
+	"default": defaultValue,
+	"year":    func() int { return time.Now().Year() },
+	"include": func(string, any) (string, error) { return "", errors.New("include is only available in templates") },
+}
+
+// words splits s into words at non-alphanumeric characters and at case
+// changes, so "myApp", "my-app", "my_app" and "MyApp" all split into "my"
+// and "app" (case kept). A run of capitals stays together: "HTTPServer"
+// gives "HTTP" and "Server".
+func words(s string) []string {
+	var out []string
+	var cur []rune
+	flush := func() {
+		if len(cur) > 0 {
+			out = append(out, string(cur))
+			cur = cur[:0]
+		}
+	}
+	runes := []rune(s)
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			flush()
+			continue
+		}
+		if len(cur) > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				flush()
+			}
+		}
+		cur = append(cur, r)
+	}
+	flush()
+	return out
+}
+
+func title(w string) string {
+	r := []rune(strings.ToLower(w))
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
+
+// Kebab converts s to kebab-case: "MyApp" -> "my-app".
+func Kebab(s string) string {
+	return strings.ToLower(strings.Join(words(s), "-"))
+}
+
+// Snake converts s to snake_case: "my-app" -> "my_app".
+func Snake(s string) string {
+	return strings.ToLower(strings.Join(words(s), "_"))
+}
+
+// Camel converts s to camelCase: "my-app" -> "myApp".
+func Camel(s string) string {
+	ws := words(s)
+	for i, w := range ws {
+		if i == 0 {
+			ws[i] = strings.ToLower(w)
+		} else {
+			ws[i] = title(w)
+		}
+	}
+	return strings.Join(ws, "")
+}
+
+// Pascal converts s to PascalCase: "my-app" -> "MyApp".
+func Pascal(s string) string {
+	ws := words(s)
+	for i, w := range ws {
+		ws[i] = title(w)
+	}
+	return strings.Join(ws, "")
+}
+
+// EnvVar converts s to an identifier usable as an environment variable
+// name: "my-app" -> "MY_APP". A leading digit is prefixed with "_".
+func EnvVar(s string) string {
+	v := strings.ToUpper(strings.Join(words(s), "_"))
+	if v != "" && unicode.IsDigit(rune(v[0])) {
+		v = "_" + v
+	}
+	return v
+}
+
+// quote returns v as a double-quoted string with Go escapes, which is also
+// a valid YAML and JSON string for printable input.
+func quote(v any) string {
+	return strconv.Quote(fmt.Sprint(v))
+}
+
+// indent prefixes every non-empty line of s with n spaces. It takes the
+// count first so it can end a pipeline: {{include "x" . | indent 4}}.
+func indent(n int, s string) string {
+	pad := strings.Repeat(" ", n)
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = pad + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// includeIn returns the include function of set: it executes the named
+// template of set with data and returns the output, so unlike the template
+// action its result can be piped on, e.g. into indent.
+func includeIn(set *template.Template) func(string, any) (string, error) {
+	return func(name string, data any) (string, error) {
+		var b strings.Builder
+		if err := set.ExecuteTemplate(&b, name, data); err != nil {
+			return "", err
+		}
+		return b.String(), nil
+	}
+}
+
+// defaultValue returns val, or def when val is nil or its type's zero value:
+// {{.Description | default "A CLI"}}.
+func defaultValue(def, val any) any {
+	if val == nil || reflect.ValueOf(val).IsZero() {
+		return def
+	}
+	return val
+}
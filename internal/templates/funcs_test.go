@@ -0,0 +1,77 @@
+package templates
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"text/template"
+	"time"
+)
+
+func TestCaseConversions(t *testing.T) {
+	tests := []struct {
+		in                                  string
+		kebab, snake, camel, pascal, envVar string
+	}{
+		{"my-app", "my-app", "my_app", "myApp", "MyApp", "MY_APP"},
+		{"my_app", "my-app", "my_app", "myApp", "MyApp", "MY_APP"},
+		{"MyApp", "my-app", "my_app", "myApp", "MyApp", "MY_APP"},
+		{"HTTPServer", "http-server", "http_server", "httpServer", "HttpServer", "HTTP_SERVER"},
+		{"go.api v2", "go-api-v2", "go_api_v2", "goApiV2", "GoApiV2", "GO_API_V2"},
+		{"3d-tools", "3d-tools", "3d_tools", "3dTools", "3dTools", "_3D_TOOLS"},
+		{"", "", "", "", "", ""},
+	}
+	for _, tt := range tests {
+		got := []string{Kebab(tt.in), Snake(tt.in), Camel(tt.in), Pascal(tt.in), EnvVar(tt.in)}
+		want := []string{tt.kebab, tt.snake, tt.camel, tt.pascal, tt.envVar}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("%q: got %v, want %v", tt.in, got, want)
+				break
+			}
+		}
+	}
+}
+
+func TestFuncs(t *testing.T) {
+	tests := []struct {
+		src  string
+		data any
+		want string
+	}{
+		{`{{quote .}}`, `say "hi"`, `"say \"hi\""`},
+		{`{{. | indent 2}}`, "a\n\nb", "  a\n\n  b"},
+		{`{{. | default "none"}}`, "", "none"},
+		{`{{. | default "none"}}`, "set", "set"},
+		{`{{. | default 8080}}`, 0, "8080"},
+		{`{{year}}`, nil, strconv.Itoa(time.Now().Year())},
+		{`{{. | snake | envVar}}`, "my-app", "MY_APP"},
+	}
+	for _, tt := range tests {
+		tmpl := template.Must(template.New("t").Funcs(funcs).Parse(tt.src))
+		var b strings.Builder
+		if err := tmpl.Execute(&b, tt.data); err != nil {
+			t.Fatalf("%s: %v", tt.src, err)
+		}
+		if b.String() != tt.want {
+			t.Errorf("%s with %v = %q, want %q", tt.src, tt.data, b.String(), tt.want)
+		}
+	}
+}
+
+func TestIncludeIndentsPartial(t *testing.T) {
+	tmpl, err := Parse("t", []byte(`{{define "steps"}}- a
+- b
+{{end}}steps:
+{{include "steps" . | indent 2}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, nil); err != nil {
+		t.Fatal(err)
+	}
+	if want := "steps:\n  - a\n  - b\n"; b.String() != want {
+		t.Errorf("got %q, want %q", b.String(), want)
+	}
+}
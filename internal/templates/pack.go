@@ -0,0 +1,173 @@
+package templates
+
+import (
+	"bytes"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"go.yaml.in/yaml/v3"
+)
+
+//go:embed pack.yaml
+var builtinPack []byte
+
+// Overwrite behaviors for a PackFile.
+const (
+	OverwriteConflict = "conflict" // follow the --on-conflict policy (default)
+	OverwriteAlways   = "always"   // always replace the existing file
+	OverwriteNever    = "never"    // only create the file; never touch an existing one
+)
+
+// Pack is a template pack manifest: the set of files a pack generates and
+// the conditions under which each is generated.
+type Pack struct {
+	Name        string     `yaml:"name"`
+	Description string     `yaml:"description,omitempty"`
+	Files       []PackFile `yaml:"files"`
+}
+
+// PackFile declares one generated file.
+type PackFile struct {
+	Name        string   `yaml:"name"`
+	Description string   `yaml:"description,omitempty"`
+	Path        string   `yaml:"path"` // slash-separated, relative to the project; may use template data
+	Template    string   `yaml:"template"`
+	Mode        string   `yaml:"mode,omitempty"`      // octal, default "0644"
+	Overwrite   string   `yaml:"overwrite,omitempty"` // see Overwrite* constants
+	Capability  string   `yaml:"capability,omitempty"`
+	CI          string   `yaml:"ci,omitempty"` // CI provider the file belongs to; skipped under others
+	Docs        bool     `yaml:"docs,omitempty"`
+	After       []string `yaml:"after,omitempty"`
+}
+
+// PackFileName is the manifest file at the root of a template pack
+// directory. The directory's other files are templates.
+const PackFileName = "pack.yaml"
+
+// BuiltinPack returns the pack embedded in gsi.
+func BuiltinPack() (*Pack, error) {
+	return ParsePack(builtinPack)
+}
+
+// ParsePack decodes and validates a pack manifest. Unknown keys are errors so
+// typos do not silently drop settings.
+func ParsePack(data []byte) (*Pack, error) {
+	var p Pack
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&p); err != nil {
+		return nil, fmt.Errorf("parsing pack: %w", err)
+	}
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// LoadPack reads the manifest of the pack in dir. A pack that only replaces
+// built-in templates needs no manifest; LoadPack then returns nil and no
+// error.
+func LoadPack(dir string) (*Pack, error) {
+	data, err := os.ReadFile(filepath.Join(dir, PackFileName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading pack: %w", err)
+	}
+	p, err := ParsePack(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", dir, err)
+	}
+	return p, nil
+}
+
+// Merge returns p with the files of layer laid over it: a layer file with the
+// name of one of p's files replaces it in place, and new files are appended.
+// Neither pack is modified.
+func (p *Pack) Merge(layer *Pack) *Pack {
+	out := &Pack{Name: p.Name, Description: p.Description, Files: append([]PackFile(nil), p.Files...)}
+	if layer == nil {
+		return out
+	}
+	index := make(map[string]int, len(out.Files))
+	for i, f := range out.Files {
+		index[f.Name] = i
+	}
+	for _, f := range layer.Files {
+		if i, ok := index[f.Name]; ok {
+			out.Files[i] = f
+			continue
+		}
+		index[f.Name] = len(out.Files)
+		out.Files = append(out.Files, f)
+	}
+	return out
+}
+
+func (p *Pack) validate() error {
+	if p.Name == "" {
+		return fmt.Errorf("pack has no name")
+	}
+	seen := make(map[string]bool, len(p.Files))
+	for i, f := range p.Files {
+		switch {
+		case f.Name == "":
+			return fmt.Errorf("pack %s: file %d has no name", p.Name, i+1)
+		case seen[f.Name]:
+			return fmt.Errorf("pack %s: duplicate file %q", p.Name, f.Name)
+		case f.Path == "":
+			return fmt.Errorf("pack %s: file %q has no path", p.Name, f.Name)
+		case strings.HasPrefix(f.Path, "/") || strings.Contains("/"+f.Path+"/", "/../"):
+			return fmt.Errorf("pack %s: file %q path %q must stay inside the project", p.Name, f.Name, f.Path)
+		case !validName(f.Template):
+			return fmt.Errorf("pack %s: file %q has invalid template %q", p.Name, f.Name, f.Template)
+		}
+		if _, err := f.FileMode(); err != nil {
+			return fmt.Errorf("pack %s: file %q: %w", p.Name, f.Name, err)
+		}
+		switch f.Overwrite {
+		case "", OverwriteConflict, OverwriteAlways, OverwriteNever:
+		default:
+			return fmt.Errorf("pack %s: file %q: invalid overwrite %q (valid: %s, %s, %s)",
+				p.Name, f.Name, f.Overwrite, OverwriteConflict, OverwriteAlways, OverwriteNever)
+		}
+		seen[f.Name] = true
+	}
+	return nil
+}
+
+// FileMode returns the parsed Mode, defaulting to 0o644.
+func (f PackFile) FileMode() (fs.FileMode, error) {
+	if f.Mode == "" {
+		return 0o644, nil
+	}
+	m, err := strconv.ParseUint(f.Mode, 8, 32)
+	if err != nil || m > 0o777 {
+		return 0, fmt.Errorf("invalid mode %q", f.Mode)
+	}
+	return fs.FileMode(m), nil
+}
+
+// RenderPath expands template data in the file's output path.
+func (f PackFile) RenderPath(data Data) (string, error) {
+	if !strings.Contains(f.Path, "{{") {
+		return f.Path, nil
+	}
+	tmpl, err := template.New(f.Name).Funcs(funcs).Option("missingkey=error").Parse(f.Path)
+	if err != nil {
+		return "", fmt.Errorf("file %q path: %w", f.Name, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("file %q path: %w", f.Name, err)
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,107 @@
+package templates
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBuiltinPack(t *testing.T) {
+	p, err := BuiltinPack()
+	if err != nil {
+		t.Fatalf("BuiltinPack failed: %v", err)
+	}
+	if len(p.Files) == 0 {
+		t.Fatal("built-in pack declares no files")
+	}
+	for _, f := range p.Files {
+		if _, err := Embedded(f.Template); err != nil {
+			t.Errorf("file %q uses missing template %q", f.Name, f.Template)
+		}
+	}
+}
+
+func TestParsePackErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		want string
+	}{
+		{"no name", "files: []", "no name"},
+		{"unknown key", "name: p\nfiles:\n  - name: a\n    path: a\n    template: a.tmpl\n    colour: red\n", "colour"},
+		{"duplicate", "name: p\nfiles:\n  - {name: a, path: a, template: a.tmpl}\n  - {name: a, path: b, template: b.tmpl}\n", "duplicate"},
+		{"no path", "name: p\nfiles:\n  - {name: a, template: a.tmpl}\n", "no path"},
+		{"escaping path", "name: p\nfiles:\n  - {name: a, path: ../a, template: a.tmpl}\n", "inside the project"},
+		{"bad template", "name: p\nfiles:\n  - {name: a, path: a, template: x/a.tmpl}\n", "invalid template"},
+		{"bad mode", "name: p\nfiles:\n  - {name: a, path: a, template: a.tmpl, mode: \"0999\"}\n", "invalid mode"},
+		{"bad overwrite", "name: p\nfiles:\n  - {name: a, path: a, template: a.tmpl, overwrite: sometimes}\n", "invalid overwrite"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParsePack([]byte(tt.yaml))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ParsePack error = %v, want one containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestPackFileModeAndPath(t *testing.T) {
+	f := PackFile{Name: "ini", Path: "{{.ProjectName}}_pycodesign.ini", Mode: "0755"}
+	mode, err := f.FileMode()
+	if err != nil || mode != 0o755 {
+		t.Errorf("FileMode = %v, %v; want 0755", mode, err)
+	}
+	if mode, _ := (PackFile{}).FileMode(); mode != 0o644 {
+		t.Errorf("default FileMode = %v, want 0644", mode)
+	}
+	path, err := f.RenderPath(Data{ProjectName: "myapp"})
+	if err != nil || path != "myapp_pycodesign.ini" {
+		t.Errorf("RenderPath = %q, %v", path, err)
+	}
+	f.Path = "{{.ProjectName | snake}}/doc.go"
+	if path, err := f.RenderPath(Data{ProjectName: "my-app"}); err != nil || path != "my_app/doc.go" {
+		t.Errorf("RenderPath with funcs = %q, %v", path, err)
+	}
+}
+
+func TestLoadPack(t *testing.T) {
+	dir := t.TempDir()
+	if p, err := LoadPack(dir); p != nil || err != nil {
+		t.Errorf("LoadPack without pack.yaml = %v, %v; want nil, nil", p, err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, PackFileName), []byte("name: acme\nfiles: []\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	p, err := LoadPack(dir)
+	if err != nil || p.Name != "acme" {
+		t.Errorf("LoadPack = %v, %v", p, err)
+	}
+}
+
+func TestPackMerge(t *testing.T) {
+	base := &Pack{Name: "builtin", Files: []PackFile{
+		{Name: "a", Path: "a", Template: "a.tmpl"},
+		{Name: "b", Path: "b", Template: "b.tmpl"},
+	}}
+	layer := &Pack{Name: "acme", Files: []PackFile{
+		{Name: "b", Path: "b2", Template: "b2.tmpl"},
+		{Name: "c", Path: "c", Template: "c.tmpl"},
+	}}
+	got := base.Merge(layer)
+
+	var paths []string
+	for _, f := range got.Files {
+		paths = append(paths, f.Path)
+	}
+	if strings.Join(paths, ",") != "a,b2,c" {
+		t.Errorf("merged paths = %v, want a,b2,c", paths)
+	}
+	if base.Files[1].Path != "b" {
+		t.Error("Merge modified the base pack")
+	}
+	if len(base.Merge(nil).Files) != 2 {
+		t.Error("merging nil should keep the base files")
+	}
+}
@@ -3,6 +3,17 @@ package templates
 import (
 	"bytes"
 	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
 	"text/template"
 )
 
@@ -12,19 +23,164 @@ var templateFS embed.FS
 // Data holds the variables available to all templates.
 type Data struct {
 	ProjectName      string
-	ProjectNameUpper string // derived: UPPER(ProjectName)
-	GoModulePath     string
-	GoModuleOwner    string // derived: 2nd segment of GoModulePath (e.g., "joescharf")
+	ProjectNameUpper string // derived: UPPER(ProjectName); prefer EnvPrefix in env var names
+
+	// Identifiers derived from ProjectName, each legal where it is used
+	BinaryName        string // command and binary name (e.g., "my-app")
+	EnvPrefix         string // environment variable prefix (e.g., "MY_APP")
+	GoPackageName     string // legal Go package name (e.g., "myapp")
+	PythonPackageName string // legal Python package name (e.g., "my_app")
+	DockerImage       string // e.g., "ghcr.io/owner/my-app"
+	DockerUser        string // container user and group (e.g., "my-app")
+	DockerRegistry    string // registry host of DockerImage (e.g., "ghcr.io")
+
+	GoModulePath  string
+	GoModuleOwner string // derived: top-level owner of the repository (e.g., "joescharf")
+
+	// Where the project is hosted, from --repo-url or the module path
+	Forge     string // "github", "gitlab", "gitea", or "" if unknown
+	RepoHost  string // e.g., "gitlab.com"
+	RepoOwner string // owner or group path (e.g., "group/sub")
+	RepoName  string // e.g., "my-app"
+	RepoURL   string // e.g., "https://gitlab.com/group/sub/my-app"; "" if unknown
+	PagesURL  string // docs site on the forge's pages hosting; "" if unknown
+	CI        string // CI provider: "github", "gitlab", "gitea" or "none"
+
+	Description  string // one-line project description (--description)
+	Author       string // as configured, e.g. "Jane Doe jane@example.com"
+	AuthorName   string // derived: Author without the email
+	AuthorEmail  string // derived: email in Author, if any
+	Year         int    // copyright year
+	License      string // SPDX identifier (e.g., "MIT"), or "none"
+	GoVersion    string // major.minor of the Go toolchain (e.g., "1.24")
+	Capabilities map[string]bool
 }
 
-// Render executes the named template with the given data and returns the result.
+// Renderer looks templates up and renders them. A template is read from the
+// first override or pack directory holding a file of its name, falling back
+// to the embedded templates, so a nil or zero Renderer renders the built-ins.
+// Each Scaffolder owns one, so concurrent scaffolds never see each other's
+// directories.
+type Renderer struct {
+	// Overrides are the user's template directories, searched first and in
+	// order. A file in one replaces the template with the same name.
+	// Empty entries and directories that do not exist are ignored.
+	Overrides []string
+	// Packs are template pack directories, searched after Overrides so a
+	// user's own overrides still win over a shared pack.
+	Packs []string
+
+	mu       sync.Mutex
+	partials *template.Template // parsed on first use
+}
+
+// PartialsDir is the directory, of the embedded templates and of override
+// and pack directories alike, holding shared partials: files of {{define}}
+// blocks every template can call. Partials are named "partials/<file>".
+const PartialsDir = "partials"
+
+var (
+	builtinOnce     sync.Once
+	builtinPartials *template.Template
+	builtinErr      error
+)
+
+// delimsDirective matches an optional first line choosing other action
+// delimiters for the rest of the file, e.g. {{/* gsi:delims [[ ]] */}}, so
+// templates for formats that use {{ }} themselves need no escaping.
+var delimsDirective = regexp.MustCompile(`^\{\{/\*\s*gsi:delims\s+(\S+)\s+(\S+)\s*\*/\}\}[ \t]*\r?\n`)
+
+// splitDelims strips a gsi:delims directive from the start of raw and
+// returns the remaining source with the delimiters it names; left and right
+// are empty (meaning {{ and }}) when there is no directive. The directive
+// line becomes a comment spanning one line, so error positions still match
+// the file.
+func splitDelims(raw []byte) (src, left, right string) {
+	m := delimsDirective.FindSubmatch(raw)
+	if m == nil {
+		return string(raw), "", ""
+	}
+	left, right = string(m[1]), string(m[2])
+	return left + "/*\n*/" + right + string(raw[len(m[0]):]), left, right
+}
+
+// basePartials returns the partials r's templates can call, parsed once
+// into a template set with the function library. Each partial is read from
+// where Lookup resolves it, so override and pack directories can replace a
+// built-in partial or add their own. Render clones the set for each template.
+func (r *Renderer) basePartials() (*template.Template, error) {
+	if len(r.dirs()) == 0 {
+		builtinOnce.Do(func() {
+			builtinPartials, builtinErr = r.parsePartials()
+		})
+		return builtinPartials, builtinErr
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.partials == nil {
+		set, err := r.parsePartials()
+		if err != nil {
+			return nil, err
+		}
+		r.partials = set
+	}
+	return r.partials, nil
+}
+
+func (r *Renderer) parsePartials() (*template.Template, error) {
+	set := template.New(PartialsDir).Funcs(funcs)
+	for _, name := range r.Names() {
+		if !strings.HasPrefix(name, PartialsDir+"/") {
+			continue
+		}
+		raw, _, err := r.Lookup(name)
+		if err != nil {
+			return nil, err
+		}
+		src, left, right := splitDelims(raw)
+		if _, err := set.New(name).Delims(left, right).Parse(src); err != nil {
+			return nil, err
+		}
+	}
+	return set, nil
+}
+
+// Parse parses the source of the named template, honouring a gsi:delims
+// directive, into a copy of the built-in partials set.
+func Parse(name string, raw []byte) (*template.Template, error) {
+	return (*Renderer)(nil).Parse(name, raw)
+}
+
+// Parse parses the source of the named template, honouring a gsi:delims
+// directive, into a copy of r's partials set.
+func (r *Renderer) Parse(name string, raw []byte) (*template.Template, error) {
+	base, err := r.basePartials()
+	if err != nil {
+		return nil, fmt.Errorf("parsing partials: %w", err)
+	}
+	set, err := base.Clone()
+	if err != nil {
+		return nil, err
+	}
+	set.Funcs(template.FuncMap{"include": includeIn(set)})
+	src, left, right := splitDelims(raw)
+	return set.New(name).Delims(left, right).Parse(src)
+}
+
+// Render executes the named built-in template with the given data and
+// returns the result.
 func Render(name string, data Data) (string, error) {
-	raw, err := templateFS.ReadFile("files/" + name)
+	return (*Renderer)(nil).Render(name, data)
+}
+
+// Render executes the named template with the given data and returns the result.
+func (r *Renderer) Render(name string, data Data) (string, error) {
+	raw, _, err := r.Lookup(name)
 	if err != nil {
 		return "", err
 	}
 
-	tmpl, err := template.New(name).Parse(string(raw))
+	tmpl, err := r.Parse(name, raw)
 	if err != nil {
 		return "", err
 	}
@@ -35,3 +191,111 @@ func Render(name string, data Data) (string, error) {
 	}
 	return buf.String(), nil
 }
+
+// Lookup returns the source of the named template and where it came from:
+// the path of an override or pack file, or "" for the embedded template.
+func (r *Renderer) Lookup(name string) ([]byte, string, error) {
+	if !validName(name) {
+		return nil, "", fmt.Errorf("invalid template name %q", name)
+	}
+	for _, dir := range r.dirs() {
+		path := filepath.Join(dir, name)
+		raw, err := os.ReadFile(path)
+		if err == nil {
+			return raw, path, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, "", fmt.Errorf("reading template override: %w", err)
+		}
+	}
+	raw, err := Embedded(name)
+	return raw, "", err
+}
+
+// Embedded returns the built-in source of the named template, ignoring any
+// overrides.
+func Embedded(name string) ([]byte, error) {
+	if !validName(name) {
+		return nil, fmt.Errorf("invalid template name %q", name)
+	}
+	return templateFS.ReadFile("files/" + name)
+}
+
+// dirs returns r's override and pack directories in lookup order, leaving
+// out empty entries.
+func (r *Renderer) dirs() []string {
+	if r == nil {
+		return nil
+	}
+	var dirs []string
+	for _, dir := range slices.Concat(r.Overrides, r.Packs) {
+		if dir != "" {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// Names returns the names of the built-in templates, partials included, in
+// sorted order.
+func Names() []string {
+	files, _ := fs.Sub(templateFS, "files")
+	names := templateFiles(files)
+	sort.Strings(names)
+	return names
+}
+
+// Names returns the names of every template r can render, partials
+// included: the built-ins and the templates its override and pack
+// directories add, in sorted order.
+func (r *Renderer) Names() []string {
+	names := Names()
+	for _, dir := range r.dirs() {
+		names = append(names, templateFiles(os.DirFS(dir))...)
+	}
+	sort.Strings(names)
+	return slices.Compact(names)
+}
+
+// templateFiles returns the names of the .tmpl files at the top of fsys and
+// under PartialsDir.
+func templateFiles(fsys fs.FS) []string {
+	var names []string
+	for _, dir := range []string{".", PartialsDir} {
+		entries, _ := fs.ReadDir(fsys, dir)
+		for _, e := range entries {
+			if !e.IsDir() && strings.HasSuffix(e.Name(), ".tmpl") {
+				names = append(names, path.Join(dir, e.Name()))
+			}
+		}
+	}
+	return names
+}
+
+// Export copies the built-in source of the named template into dir, as a
+// starting point for an override. An existing file is left alone unless
+// overwrite is set; written reports whether the file was written.
+func Export(dir, name string, overwrite bool) (written bool, err error) {
+	raw, err := Embedded(name)
+	if err != nil {
+		return false, err
+	}
+	path := filepath.Join(dir, name)
+	if _, err := os.Stat(path); err == nil && !overwrite {
+		return false, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return false, fmt.Errorf("creating %s: %w", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, raw, 0o644); err != nil {
+		return false, fmt.Errorf("writing %s: %w", path, err)
+	}
+	return true, nil
+}
+
+// validName accepts a file name, or one under PartialsDir, and rejects names
+// that would escape a template directory.
+func validName(name string) bool {
+	name = strings.TrimPrefix(name, PartialsDir+"/")
+	return name != "" && !strings.ContainsAny(name, `/\`) && name != "." && name != ".."
+}
@@ -1,16 +1,34 @@
 package templates
 
 import (
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
+
+	"go.yaml.in/yaml/v3"
 )
 
 func TestRenderWithVariables(t *testing.T) {
 	data := Data{
 		ProjectName:      "myapp",
 		ProjectNameUpper: "MYAPP",
+		BinaryName:       "myapp",
+		EnvPrefix:        "MYAPP",
+		DockerImage:      "ghcr.io/example/myapp",
+		DockerUser:       "myapp",
+		DockerRegistry:   "ghcr.io",
 		GoModulePath:     "github.com/example/myapp",
 		GoModuleOwner:    "example",
+		Forge:            "github",
+		RepoHost:         "github.com",
+		RepoOwner:        "example",
+		RepoName:         "myapp",
+		RepoURL:          "https://github.com/example/myapp",
+		PagesURL:         "https://example.github.io/myapp/",
+		Capabilities:     map[string]bool{"ui": true, "docs": true, "docker": true, "release": true, "goreleaser": true},
 	}
 
 	tests := []struct {
@@ -35,10 +53,17 @@ func TestRenderWithVariables(t *testing.T) {
 		{"docs_scripts_scrape_sh.tmpl", []string{"#!/bin/bash", `--title "myapp"`, "shot-scraper"}},
 		{"docs_scripts_shots_yaml.tmpl", []string{"myapp-dashboard.png", "localhost:8080"}},
 		{"docs_scripts_add_browser_frame_py.tmpl", []string{"#!/usr/bin/env python3", "pillow", "SUPERSAMPLE_SCALE"}},
-		{"github_release_yml.tmpl", []string{"go-version-file: go.mod", "oven-sh/setup-bun", "docker/setup-qemu-action", "docker/setup-buildx-action", "docker/login-action", "version: latest"}},
+		{"github_release_yml.tmpl", []string{"go-version-file: go.mod", "oven-sh/setup-bun", "docker/setup-qemu-action", "docker/setup-buildx-action", "docker/login-action", "registry: ghcr.io", "version: latest"}},
 		{"golangci_yml.tmpl", []string{`version: "2"`, "errcheck", "fmt.Fprintf"}},
 		{"github_ci_yml.tmpl", []string{"go-version-file: go.mod", "oven-sh/setup-bun", "go test", "go vet", "golangci-lint"}},
 		{"github_docs_yml.tmpl", []string{"astral-sh/setup-uv", "mkdocs build", "upload-pages-artifact", "deploy-pages"}},
+		{"gitlab_ci_yml.tmpl", []string{"stages:\n  - build\n  - test", "local: .gitlab/ci/*.yml"}},
+		{"gitlab_ci_jobs_yml.tmpl", []string{"image: oven/bun:1", "needs: [ui]", "go test -v -race", "golangci-lint run"}},
+		{"gitlab_release_jobs_yml.tmpl", []string{"goreleaser release --clean", "docker:dind", "$CI_REGISTRY_USER", "npm install -g bun", "if: $CI_COMMIT_TAG"}},
+		{"gitlab_pages_jobs_yml.tmpl", []string{"pages:", "mkdocs build --site-dir ../public", "- public"}},
+		{"gitea_ci_yml.tmpl", []string{"go-version-file: go.mod", "oven-sh/setup-bun", "go test", "golangci-lint"}},
+		{"gitea_release_yml.tmpl", []string{"tags: [\"v*\"]", "registry: ghcr.io", "${{ gitea.actor }}", "GITEA_TOKEN: ${{ secrets.RELEASE_TOKEN }}"}},
+		{"gitea_docs_yml.tmpl", []string{"astral-sh/setup-uv", "mkdocs gh-deploy --force --remote-branch pages"}},
 		{"main_go.tmpl", []string{"github.com/example/myapp/cmd", "cmd.Execute(version, commit, date)"}},
 		{"cmd_root_go.tmpl", []string{"package cmd", "func Execute(version, commit, date string)", "buildVersion"}},
 		{"cmd_version.go.tmpl", []string{"package cmd", "buildVersion", "buildCommit", "buildDate"}},
@@ -61,9 +86,458 @@ func TestRenderWithVariables(t *testing.T) {
 	}
 }
 
+func TestRenderProjectMetadata(t *testing.T) {
+	data := Data{
+		ProjectName:   "myapp",
+		GoModulePath:  "github.com/example/myapp",
+		GoModuleOwner: "example",
+		Forge:         "github",
+		RepoOwner:     "example",
+		RepoName:      "myapp",
+		RepoURL:       "https://github.com/example/myapp",
+		Description:   "Syncs inventory",
+		AuthorName:    "Jane Doe",
+		Year:          2026,
+		License:       "Apache-2.0",
+		GoVersion:     "1.24",
+		Capabilities:  map[string]bool{"release": true},
+	}
+	tests := []struct {
+		template string
+		contains []string
+	}{
+		{"goreleaser_yml.tmpl", []string{`description: "Syncs inventory"`}},
+		{"mkdocs_yml.tmpl", []string{`site_description: "Syncs inventory"`, `site_author: "Jane Doe"`, "2026 Jane Doe"}},
+		{"docs_index_md.tmpl", []string{"Syncs inventory"}},
+		{"docs_getting_started_md.tmpl", []string{"Requires Go 1.24"}},
+		{"cmd_root_go.tmpl", []string{`Short: "Syncs inventory",`}},
+		{"license.tmpl", []string{"Apache License", "Version 2.0", "Copyright 2026 Jane Doe"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			got, err := Render(tt.template, data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(got, want) {
+					t.Errorf("expected %q in output", want)
+				}
+			}
+		})
+	}
+
+	data.License = "none"
+	if got, err := Render("license.tmpl", data); err != nil || strings.TrimSpace(got) != "" {
+		t.Errorf("license none rendered %q, %v", got, err)
+	}
+}
+
+func TestRenderQuotesFreeTextInYAML(t *testing.T) {
+	data := Data{
+		ProjectName:  "myapp",
+		BinaryName:   "myapp",
+		GoModulePath: "github.com/example/myapp",
+		Forge:        "github",
+		RepoURL:      "https://github.com/example/myapp",
+		Description:  `A "fast": tool \ for C:\data`,
+		AuthorName:   `Jane "JD" Doe`,
+		Year:         2026,
+		Capabilities: map[string]bool{"release": true, "docker": true},
+	}
+	for _, tt := range []struct {
+		template string
+		want     string
+		field    func(map[string]any) any
+	}{
+		{"mkdocs_yml.tmpl", data.Description, func(doc map[string]any) any { return doc["site_description"] }},
+		{"mkdocs_yml.tmpl", data.AuthorName, func(doc map[string]any) any { return doc["site_author"] }},
+		{"mkdocs_yml.tmpl", "Copyright &copy; 2026 " + data.AuthorName, func(doc map[string]any) any { return doc["copyright"] }},
+		{"goreleaser_yml.tmpl", data.Description, func(doc map[string]any) any {
+			casks, _ := doc["homebrew_casks"].([]any)
+			if len(casks) == 0 {
+				return nil
+			}
+			return casks[0].(map[string]any)["description"]
+		}},
+	} {
+		got, err := Render(tt.template, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var doc map[string]any
+		if err := yaml.Unmarshal([]byte(got), &doc); err != nil {
+			t.Fatalf("%s is not valid YAML: %v\n%s", tt.template, err, got)
+		}
+		if v := tt.field(doc); v != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.template, v, tt.want)
+		}
+	}
+}
+
+func TestRenderCapabilities(t *testing.T) {
+	type want struct {
+		template    string
+		contains    []string
+		notContains []string
+	}
+	ui := []string{"oven-sh/setup-bun", "bun install"}
+	tests := []struct {
+		name string
+		caps map[string]bool
+		want []want
+	}{
+		{
+			name: "defaults",
+			caps: map[string]bool{"docs": true, "docker": true, "release": true, "goreleaser": true},
+			want: []want{
+				{"github_ci_yml.tmpl", []string{"go test", "golangci-lint"}, ui},
+				{"github_release_yml.tmpl", []string{"docker/login-action", "packages: write", "HOMEBREW_TAP_TOKEN"}, ui},
+				{"goreleaser_yml.tmpl", []string{"go mod download", "homebrew_casks:", "dockers_v2:"}, []string{"cd ui"}},
+				{"dockerfile.tmpl", []string{`ENTRYPOINT ["myapp"]`}, []string{"CMD", "EXPOSE"}},
+				{"makefile.tmpl", []string{"docs-serve:", "release:", "HOMEBREW_TAP_TOKEN", "run docs-serve 2>"}, []string{"ui-dev", "ui/package.json"}},
+				{"gitlab_ci_yml.tmpl", []string{"stages:\n  - test"}, []string{"build"}},
+				{"gitlab_ci_jobs_yml.tmpl", []string{"test:", "lint:"}, []string{"ui:", "needs:"}},
+				{"gitlab_release_jobs_yml.tmpl", []string{"docker:dind"}, []string{"bun"}},
+			},
+		},
+		{
+			name: "ui",
+			caps: map[string]bool{"ui": true, "docker": true, "release": true, "goreleaser": true},
+			want: []want{
+				{"github_ci_yml.tmpl", ui, nil},
+				{"github_release_yml.tmpl", []string{"oven-sh/setup-bun", "docker/setup-buildx-action"}, nil},
+				{"goreleaser_yml.tmpl", []string{"cd ui && bun install --frozen-lockfile", "cp -r ui/dist/*"}, nil},
+				{"dockerfile.tmpl", []string{"EXPOSE 8080", `CMD ["serve"]`}, nil},
+				{"makefile.tmpl", []string{"ui-dev:", "ui-build ui-embed", "run ui-dev 2>"}, []string{"docs-serve", "docs/mkdocs.yml"}},
+			},
+		},
+		{
+			name: "no docker or homebrew",
+			caps: map[string]bool{"goreleaser": true},
+			want: []want{
+				{"github_release_yml.tmpl", []string{"goreleaser/goreleaser-action"}, []string{"docker/", "packages: write"}},
+				{"goreleaser_yml.tmpl", []string{"archives:", "prerelease: auto"}, []string{"homebrew_casks:", "dockers_v2:", "ghcr.io"}},
+				{"gitlab_release_jobs_yml.tmpl", []string{"goreleaser release"}, []string{"docker", "before_script"}},
+				{"makefile.tmpl", []string{"release:", "dev: run"}, []string{"HOMEBREW_TAP_TOKEN", "docs-", "ui-"}},
+			},
+		},
+		{
+			name: "no goreleaser",
+			caps: map[string]bool{},
+			want: []want{
+				{"makefile.tmpl", []string{"build:", "help:"}, []string{"goreleaser", "##@ Release"}},
+				{"gitlab_ci_yml.tmpl", nil, []string{"stages", "include"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		data := Data{ProjectName: "myapp", BinaryName: "myapp", GoModulePath: "github.com/example/myapp", GoModuleOwner: "example",
+			Forge: "github", RepoHost: "github.com", RepoOwner: "example", RepoName: "myapp", Capabilities: tt.caps}
+		for _, w := range tt.want {
+			t.Run(tt.name+"/"+w.template, func(t *testing.T) {
+				got, err := Render(w.template, data)
+				if err != nil {
+					t.Fatal(err)
+				}
+				for _, s := range w.contains {
+					if !strings.Contains(got, s) {
+						t.Errorf("expected %q in output:\n%s", s, got)
+					}
+				}
+				for _, s := range w.notContains {
+					if strings.Contains(got, s) {
+						t.Errorf("unexpected %q in output:\n%s", s, got)
+					}
+				}
+			})
+		}
+	}
+}
+
 func TestRenderMissingTemplate(t *testing.T) {
 	_, err := Render("nonexistent.tmpl", Data{})
 	if err == nil {
 		t.Error("expected error for missing template, got nil")
 	}
 }
+
+func TestRenderPrefersOverride(t *testing.T) {
+	first, second := t.TempDir(), t.TempDir()
+	if err := os.WriteFile(filepath.Join(second, "editorconfig.tmpl"), []byte("second {{.ProjectName}}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	r := &Renderer{Overrides: []string{first, "", second}}
+
+	got, err := r.Render("editorconfig.tmpl", Data{ProjectName: "myapp"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "second myapp" {
+		t.Errorf("expected override to be used, got %q", got)
+	}
+
+	// Templates without an override still come from the embedded files
+	if _, source, err := r.Lookup("makefile.tmpl"); err != nil || source != "" {
+		t.Errorf("expected embedded makefile.tmpl, got source %q, err %v", source, err)
+	}
+
+	// The first directory on the path wins
+	if err := os.WriteFile(filepath.Join(first, "editorconfig.tmpl"), []byte("first"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, source, _ := r.Lookup("editorconfig.tmpl"); source != filepath.Join(first, "editorconfig.tmpl") {
+		t.Errorf("expected first override to win, got %q", source)
+	}
+}
+
+func TestLookupPackPath(t *testing.T) {
+	override, pack := t.TempDir(), t.TempDir()
+	for _, dir := range []string{override, pack} {
+		if err := os.WriteFile(filepath.Join(dir, "dockerfile.tmpl"), []byte(dir), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(pack, "makefile.tmpl"), []byte("pack"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	r := &Renderer{Overrides: []string{override}, Packs: []string{pack}}
+
+	// User overrides beat packs, packs beat the built-ins
+	if _, source, _ := r.Lookup("dockerfile.tmpl"); source != filepath.Join(override, "dockerfile.tmpl") {
+		t.Errorf("expected override to win over pack, got %q", source)
+	}
+	if _, source, _ := r.Lookup("makefile.tmpl"); source != filepath.Join(pack, "makefile.tmpl") {
+		t.Errorf("expected pack template, got %q", source)
+	}
+	if _, source, _ := r.Lookup("gitignore.tmpl"); source != "" {
+		t.Errorf("expected embedded gitignore.tmpl, got %q", source)
+	}
+}
+
+func TestLookupRejectsPaths(t *testing.T) {
+	for _, name := range []string{"", "../secret", "files/main_go.tmpl", "..", "partials/../x.tmpl", "partials/"} {
+		if _, _, err := (&Renderer{}).Lookup(name); err == nil {
+			t.Errorf("expected error for template name %q", name)
+		}
+	}
+}
+
+func TestNames(t *testing.T) {
+	names := Names()
+	if !slices.Contains(names, "main_go.tmpl") || !slices.IsSorted(names) {
+		t.Errorf("unexpected template names %v", names)
+	}
+	if slices.Contains(names, PartialsDir) || !slices.Contains(names, "partials/github.tmpl") {
+		t.Errorf("expected partials listed by file, got %v", names)
+	}
+
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, PartialsDir), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"extra.tmpl", "pack.yaml", "partials/mine.tmpl"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	names = (&Renderer{Packs: []string{dir}}).Names()
+	for _, want := range []string{"extra.tmpl", "partials/mine.tmpl", "main_go.tmpl"} {
+		if !slices.Contains(names, want) {
+			t.Errorf("expected %s in %v", want, names)
+		}
+	}
+	if slices.Contains(names, "pack.yaml") || !slices.IsSorted(names) {
+		t.Errorf("unexpected template names %v", names)
+	}
+}
+
+func TestRenderPartialsFromDirectories(t *testing.T) {
+	override, pack := t.TempDir(), t.TempDir()
+	for _, dir := range []string{override, pack} {
+		if err := os.MkdirAll(filepath.Join(dir, PartialsDir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files := map[string]string{
+		filepath.Join(override, "partials", "github.tmpl"): `{{define "github-setup-go"}}setup-go{{end}}`,
+		filepath.Join(pack, "partials", "pack.tmpl"):       `{{define "pack-greeting"}}hello {{.ProjectName}}{{end}}`,
+		filepath.Join(pack, "ci.tmpl"):                     `{{template "github-setup-go"}} {{template "pack-greeting" .}}`,
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	r := &Renderer{Overrides: []string{override}, Packs: []string{pack}}
+
+	got, err := r.Render("ci.tmpl", Data{ProjectName: "app"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "setup-go hello app" {
+		t.Errorf("expected the overridden and pack partials, got %q", got)
+	}
+	if _, source, err := r.Lookup("partials/github.tmpl"); err != nil || source != filepath.Join(override, "partials", "github.tmpl") {
+		t.Errorf("expected the partial override, got source %q, err %v", source, err)
+	}
+
+	// A renderer without the directories keeps the built-in partials
+	got, err = Render("github_ci_yml.tmpl", Data{ProjectName: "app", Capabilities: map[string]bool{}})
+	if err != nil || !strings.Contains(got, "actions/setup-go") {
+		t.Errorf("expected the built-in partial, got %q, %v", got, err)
+	}
+}
+
+func TestRenderForges(t *testing.T) {
+	caps := map[string]bool{"release": true, "goreleaser": true, "docker": true, "docs": true}
+	tests := []struct {
+		name        string
+		data        Data
+		template    string
+		contains    []string
+		notContains []string
+	}{
+		{
+			name: "gitlab nested group",
+			data: Data{Forge: "gitlab", RepoHost: "gitlab.com", RepoOwner: "group/sub", RepoName: "myapp", GoModuleOwner: "group",
+				RepoURL: "https://gitlab.com/group/sub/myapp", PagesURL: "https://group.gitlab.io/sub/myapp/"},
+			template:    "goreleaser_yml.tmpl",
+			contains:    []string{"  gitlab:\n    owner: group/sub\n    name: myapp", "token_type: gitlab", `homepage: "https://gitlab.com/group/sub/myapp"`},
+			notContains: []string{"github", "gitlab_urls"},
+		},
+		{
+			name: "self-hosted gitea",
+			data: Data{Forge: "gitea", RepoHost: "git.acme.dev", RepoOwner: "tools", RepoName: "myapp", GoModuleOwner: "tools",
+				RepoURL: "https://git.acme.dev/tools/myapp"},
+			template: "goreleaser_yml.tmpl",
+			contains: []string{"gitea_urls:\n  api: https://git.acme.dev/api/v1", "  gitea:\n    owner: tools", "token_type: gitea"},
+		},
+		{
+			name:        "unknown forge",
+			data:        Data{RepoHost: "go.acme.dev", RepoName: "myapp"},
+			template:    "goreleaser_yml.tmpl",
+			contains:    []string{"release:\n  ids:"},
+			notContains: []string{"owner:", "homebrew_casks:"},
+		},
+		{
+			name: "gitlab docs",
+			data: Data{Forge: "gitlab", RepoOwner: "group/sub", RepoName: "myapp",
+				RepoURL: "https://gitlab.com/group/sub/myapp", PagesURL: "https://group.gitlab.io/sub/myapp/"},
+			template: "mkdocs_yml.tmpl",
+			contains: []string{"site_url: https://group.gitlab.io/sub/myapp/", "repo_url: https://gitlab.com/group/sub/myapp",
+				"repo_name: group/sub/myapp", "edit_uri: -/edit/main/docs/docs/"},
+		},
+		{
+			name:        "unknown forge docs",
+			data:        Data{RepoName: "myapp"},
+			template:    "mkdocs_yml.tmpl",
+			notContains: []string{"site_url", "repo_url", "edit_uri"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.data.ProjectName, tt.data.BinaryName, tt.data.Capabilities = "myapp", "myapp", caps
+			got, err := Render(tt.template, tt.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, s := range tt.contains {
+				if !strings.Contains(got, s) {
+					t.Errorf("expected %q in output:\n%s", s, got)
+				}
+			}
+			for _, s := range tt.notContains {
+				if strings.Contains(got, s) {
+					t.Errorf("unexpected %q in output:\n%s", s, got)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderDelimsAndPartials(t *testing.T) {
+	dir := t.TempDir()
+	src := "{{/* gsi:delims <% %> */}}\nname: <% .ProjectName | kebab %> {{ .Version }}\n<%- template \"github-setup-go\" %>\n"
+	if err := os.WriteFile(filepath.Join(dir, "ci.tmpl"), []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	r := &Renderer{Overrides: []string{dir}}
+
+	got, err := r.Render("ci.tmpl", Data{ProjectName: "MyApp"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "name: my-app {{ .Version }}\n\n      - uses: actions/setup-go@v5\n        with:\n          go-version-file: go.mod\n"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	// Errors point at lines of the file, directive included
+	tmpl, err := Parse("x.tmpl", []byte("{{/* gsi:delims <% %> */}}\nok\n<% .Nope %>\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tmpl.Execute(io.Discard, Data{}); err == nil || !strings.Contains(err.Error(), "x.tmpl:3:") {
+		t.Errorf("expected an error on line 3, got %v", err)
+	}
+
+	// Without the directive the default delimiters apply
+	tmpl, err = Parse("plain", []byte("<% .X %> {{.ProjectName}}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, Data{ProjectName: "app"}); err != nil || b.String() != "<% .X %> app" {
+		t.Errorf("got %q, %v", b.String(), err)
+	}
+
+	// Built-in templates need no escaping of the delimiters they emit
+	for _, name := range []string{"goreleaser_yml.tmpl", "github_release_yml.tmpl", "github_docs_yml.tmpl"} {
+		raw, _ := Embedded(name)
+		if strings.Contains(string(raw), `{{"{{"}}`) {
+			t.Errorf("%s still escapes {{", name)
+		}
+	}
+	got, err = Render("goreleaser_yml.tmpl", Data{ProjectName: "myapp"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(got, "-X main.version={{ .Version }}"); n != 3 {
+		t.Errorf("expected the shared ldflags in all 3 builds, got %d", n)
+	}
+}
+
+func TestExport(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "templates")
+
+	written, err := Export(dir, "editorconfig.tmpl", false)
+	if err != nil || !written {
+		t.Fatalf("Export = %v, %v", written, err)
+	}
+	got, _ := os.ReadFile(filepath.Join(dir, "editorconfig.tmpl"))
+	want, _ := Embedded("editorconfig.tmpl")
+	if string(got) != string(want) {
+		t.Error("exported template should match the embedded source")
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "editorconfig.tmpl"), []byte("custom"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if written, _ := Export(dir, "editorconfig.tmpl", false); written {
+		t.Error("existing file should not be overwritten without overwrite")
+	}
+	if written, _ := Export(dir, "editorconfig.tmpl", true); !written {
+		t.Error("overwrite should replace the existing file")
+	}
+	if written, err := Export(dir, "partials/github.tmpl", false); err != nil || !written {
+		t.Errorf("expected the partial to be exported, got %v, %v", written, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, PartialsDir, "github.tmpl")); err != nil {
+		t.Errorf("expected the partial under %s: %v", PartialsDir, err)
+	}
+	if _, err := Export(dir, "nonexistent.tmpl", false); err == nil {
+		t.Error("expected error for unknown template")
+	}
+}